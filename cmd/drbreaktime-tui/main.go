@@ -0,0 +1,274 @@
+// drbreaktime-tui plays a single player game of drbreaktime in the terminal
+//
+// controls are arrow keys or a/s/d to move and drop, z/x or up to rotate
+// and q to quit
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"example.com/drbreakboard"
+	"github.com/rs/zerolog"
+)
+
+// frames per second the game is simulated at
+const framesPerSecond = 60
+
+var speedNames = []string{"LOW", "MED", "HI"}
+
+func main() {
+	level := flag.Int("level", 0, "starting virus level, 0 to 20")
+	speed := flag.String("speed", "low", "capsule speed, low, med or hi")
+	seed := flag.Int64("seed", 0, "game seed, 0 picks one from the clock")
+	bag := flag.Bool("bag", false, "use the bag capsule randomizer instead of the classic one")
+	record := flag.String("record", "", "write a replay of the last game played to this file")
+	match := flag.Int("match", 4, "same colors in a line that clear")
+	colors := flag.Int("colors", 3, "colors in play from 2 to 4, 4 adds green")
+	diagonals := flag.Bool("diagonals", false, "diagonal lines clear too")
+	flag.Parse()
+
+	// board logging would draw over the game
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+
+	config := drbreakboard.GameConfig{Level: *level, Seed: *seed}
+	switch strings.ToLower(*speed) {
+	case "low":
+		config.Speed = drbreakboard.Low
+	case "med":
+		config.Speed = drbreakboard.Med
+	case "hi":
+		config.Speed = drbreakboard.Hi
+	default:
+		fmt.Fprintf(os.Stderr, "unknown speed %q\n", *speed)
+		os.Exit(2)
+	}
+	if *bag {
+		config.Randomizer = drbreakboard.BagRandomizer
+	}
+
+	config.Ruleset = drbreakboard.ClassicRuleset
+	config.Ruleset.MinMatchLength = *match
+	config.Ruleset.ColorCount = *colors
+	config.Ruleset.Diagonals = *diagonals
+	if _, err := drbreakboard.NewPlayFieldWithRuleset(1, 1, config.Ruleset); err != nil {
+		fmt.Fprintf(os.Stderr, "bad rules: %v\n", err)
+		os.Exit(2)
+	}
+
+	term, err := openTerminal()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not set up terminal: %v\n", err)
+		os.Exit(1)
+	}
+
+	// restore the terminal on ctrl-c
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		term.close()
+		os.Exit(130)
+	}()
+
+	keys := make(chan keyPress, 64)
+	go readKeys(keys)
+
+	err = run(config, *seed == 0, *record, keys)
+	term.close()
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "game stopped: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// alternate between picking a level and playing until the player quits
+func run(config drbreakboard.GameConfig, randomSeed bool, recordPath string, keys <-chan keyPress) error {
+	for {
+		var quit bool
+		config, quit = selectLevel(config, keys)
+		if quit {
+			return nil
+		}
+
+		if randomSeed {
+			config.Seed = time.Now().UnixNano()
+		}
+
+		recorder, err := drbreakboard.NewReplayRecorder(config)
+		if err != nil {
+			return err
+		}
+
+		quit, err = play(recorder, keys)
+
+		if recordPath != "" {
+			if writeErr := writeReplay(recordPath, recorder.GetReplay()); writeErr != nil && err == nil {
+				err = writeErr
+			}
+		}
+
+		if err != nil || quit {
+			return err
+		}
+	}
+}
+
+// menu for choosing level and speed
+// returns true if the player quit
+func selectLevel(config drbreakboard.GameConfig, keys <-chan keyPress) (drbreakboard.GameConfig, bool) {
+	for {
+		var sb strings.Builder
+		sb.WriteString(cursorHome + clearScreen)
+		sb.WriteString("DRBREAKTIME\n\n")
+		fmt.Fprintf(&sb, "  virus level  < %2v >\n", config.Level)
+		fmt.Fprintf(&sb, "  speed        < %v >\n\n", speedNames[config.Speed])
+		sb.WriteString("left/right level, up/down speed\n")
+		sb.WriteString("enter to start, q to quit\n")
+		os.Stdout.WriteString(sb.String())
+
+		press, ok := <-keys
+		if !ok {
+			return config, true
+		}
+
+		switch {
+		case press.key == keyLeft && config.Level > 0:
+			config.Level -= 1
+		case press.key == keyRight && config.Level < drbreakboard.MaxVirusLevel:
+			config.Level += 1
+		case press.key == keyUp && config.Speed < drbreakboard.Hi:
+			config.Speed += 1
+		case press.key == keyDown && config.Speed > drbreakboard.Low:
+			config.Speed -= 1
+		case press.key == keyEnter:
+			return config, false
+		case press.key == keyRune && press.char == 'q':
+			return config, true
+		}
+	}
+}
+
+// write a replay file
+func writeReplay(path string, replay drbreakboard.Replay) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return drbreakboard.WriteReplay(file, replay)
+}
+
+// play a game to the end, one tick per frame
+// returns true if the player quit
+func play(recorder *drbreakboard.ReplayRecorder, keys <-chan keyPress) (bool, error) {
+	game := recorder.GetGame()
+	os.Stdout.WriteString(clearScreen)
+
+	ticker := time.NewTicker(time.Second / framesPerSecond)
+	defer ticker.Stop()
+
+	actions := make([]drbreakboard.Action, 0)
+
+	for {
+		select {
+		case press, ok := <-keys:
+			if !ok {
+				return true, nil
+			}
+
+			if press.key == keyRune && press.char == 'q' {
+				return true, nil
+			}
+
+			if game.IsOver() {
+				if press.key == keyEnter {
+					return false, nil
+				}
+				continue
+			}
+
+			if action := getAction(press); action != drbreakboard.ActionNone {
+				actions = append(actions, action)
+			}
+		case <-ticker.C:
+			err := recorder.Tick(actions...)
+			if err != nil {
+				return false, err
+			}
+			actions = actions[:0]
+
+			draw(game)
+		}
+	}
+}
+
+// map a key press to a game action
+func getAction(press keyPress) drbreakboard.Action {
+	switch press.key {
+	case keyLeft:
+		return drbreakboard.ActionMoveLeft
+	case keyRight:
+		return drbreakboard.ActionMoveRight
+	case keyDown:
+		return drbreakboard.ActionSoftDrop
+	case keyUp:
+		return drbreakboard.ActionRotateClockwise
+	case keyRune:
+		switch press.char {
+		case 'a':
+			return drbreakboard.ActionMoveLeft
+		case 'd':
+			return drbreakboard.ActionMoveRight
+		case 's':
+			return drbreakboard.ActionSoftDrop
+		case 'x':
+			return drbreakboard.ActionRotateClockwise
+		case 'z':
+			return drbreakboard.ActionRotateCounterClockwise
+		}
+	}
+
+	return drbreakboard.ActionNone
+}
+
+// draw the board and game status
+func draw(game *drbreakboard.Game) {
+	var sb strings.Builder
+	sb.WriteString(cursorHome)
+
+	renderer := drbreakboard.ANSIRenderer{Border: true}
+	if pending, ok := game.GetPendingIteration(); ok && game.GetPhase() == drbreakboard.PhaseClear {
+		renderer.Overlay = pending.Field
+	}
+	renderer.Render(&sb, game.GetField())
+
+	config := game.GetConfig()
+	next := game.GetNextCapsule()
+	nextField := drbreakboard.NewPlayField(2, 1)
+	nextLeft, nextRight, _ := drbreakboard.MakeLinkedPillSpaces(drbreakboard.Right, next.Left, next.Right)
+	nextField.PutTwoLinkedSpacesAtCoordinate(0, 0, nextLeft, nextRight)
+
+	sb.WriteString("next ")
+	drbreakboard.ANSIRenderer{}.Render(&sb, nextField)
+	fmt.Fprintf(&sb, "level %v  speed %v"+clearLine+"\n", config.Level, speedNames[config.Speed])
+	fmt.Fprintf(&sb, "virus %v"+clearLine+"\n", game.GetField().GetVirusCount())
+	fmt.Fprintf(&sb, "score %v"+clearLine+"\n", game.GetScorer().GetTotal())
+
+	switch game.GetState() {
+	case drbreakboard.Won:
+		sb.WriteString("STAGE CLEAR, enter for menu, q to quit" + clearLine + "\n")
+	case drbreakboard.Lost:
+		sb.WriteString("GAME OVER, enter for menu, q to quit" + clearLine + "\n")
+	default:
+		sb.WriteString(clearLine + "\n")
+	}
+
+	os.Stdout.WriteString(sb.String())
+}
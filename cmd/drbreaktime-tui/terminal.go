@@ -0,0 +1,109 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminal escape sequences
+const (
+	clearScreen = "\x1b[2J"
+	cursorHome  = "\x1b[H"
+	clearLine   = "\x1b[K"
+	hideCursor  = "\x1b[?25l"
+	showCursor  = "\x1b[?25h"
+)
+
+// keys read from the terminal
+type key int
+
+const (
+	keyNone key = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyRune
+)
+
+type keyPress struct {
+	key  key
+	char byte
+}
+
+// puts the terminal into raw input mode and restores it when done
+// uses stty so no platform specific terminal calls are needed
+type terminal struct {
+	savedState string
+}
+
+// switch stdin to unbuffered input without echo
+func openTerminal() (*terminal, error) {
+	saved, err := runStty("-g")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = runStty("-icanon", "-echo", "min", "1", "time", "0")
+	if err != nil {
+		return nil, err
+	}
+
+	os.Stdout.WriteString(hideCursor + clearScreen)
+	return &terminal{strings.TrimSpace(saved)}, nil
+}
+
+// restore the terminal to how it was before openTerminal
+func (term *terminal) close() {
+	os.Stdout.WriteString(showCursor + "\n")
+	runStty(term.savedState)
+}
+
+func runStty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
+
+// read key presses from stdin until it closes
+// arrow keys arrive as escape sequences and are turned into single keys
+func readKeys(keys chan<- keyPress) {
+	buffer := make([]byte, 32)
+
+	for {
+		count, err := os.Stdin.Read(buffer)
+		if err != nil {
+			close(keys)
+			return
+		}
+
+		input := buffer[:count]
+		for len(input) > 0 {
+			if len(input) >= 3 && input[0] == 0x1b && input[1] == '[' {
+				switch input[2] {
+				case 'A':
+					keys <- keyPress{keyUp, 0}
+				case 'B':
+					keys <- keyPress{keyDown, 0}
+				case 'C':
+					keys <- keyPress{keyRight, 0}
+				case 'D':
+					keys <- keyPress{keyLeft, 0}
+				}
+				input = input[3:]
+				continue
+			}
+
+			switch input[0] {
+			case '\r', '\n':
+				keys <- keyPress{keyEnter, 0}
+			default:
+				keys <- keyPress{keyRune, input[0]}
+			}
+			input = input[1:]
+		}
+	}
+}
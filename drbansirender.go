@@ -0,0 +1,129 @@
+package drbreakboard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ANSI escape sequences used by the renderer
+const (
+	ansiReset      = "\x1b[0m"
+	ansiReverse    = "\x1b[7m"
+	ansiForeground = "\x1b[38;5;%dm"
+	ansiBackground = "\x1b[48;5;%dm"
+)
+
+// 256 color palette entries
+const (
+	ansiRed       = 196
+	ansiBlue      = 39
+	ansiYellow    = 226
+	ansiGreen     = 46
+	ansiFallShade = 238
+	ansiBorder    = 244
+)
+
+// draws the board with ANSI 256 color escape codes and unicode glyphs
+// each space is two columns wide so linked halves join into one capsule.
+// the active capsule is drawn where it is
+type ANSIRenderer struct {
+	// next iteration to highlight, Clear spaces are drawn reversed and
+	// Fall spaces shaded. nil draws no overlay
+	Overlay [][]NextIteration
+	// draw walls and a floor around the board
+	Border bool
+}
+
+// write the board to w
+func (renderer ANSIRenderer) Render(w io.Writer, field *PlayField) error {
+	var sb strings.Builder
+
+	for y := 0; y < field.GetHeight(); y++ {
+		if renderer.Border {
+			sb.WriteString(ansiBorderString("│"))
+		}
+
+		for x := 0; x < field.GetWidth(); x++ {
+			space, _ := field.GetDrawnSpaceAtCoordinate(y, x)
+
+			sb.WriteString(renderer.getOverlayEscape(y, x))
+			sb.WriteString(generateANSISpaceString(space))
+			sb.WriteString(ansiReset)
+		}
+
+		if renderer.Border {
+			sb.WriteString(ansiBorderString("│"))
+		}
+		sb.WriteString("\n")
+	}
+
+	if renderer.Border {
+		sb.WriteString(ansiBorderString("└" + strings.Repeat("──", field.GetWidth()) + "┘"))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// get the escape sequence highlighting a space's next iteration
+func (renderer ANSIRenderer) getOverlayEscape(y int, x int) string {
+	if y >= len(renderer.Overlay) || x >= len(renderer.Overlay[y]) {
+		return ""
+	}
+
+	switch renderer.Overlay[y][x] {
+	case Clear:
+		return ansiReverse
+	case Fall:
+		return fmt.Sprintf(ansiBackground, ansiFallShade)
+	}
+
+	return ""
+}
+
+// get the two column colored glyph for a space
+func generateANSISpaceString(space Space) string {
+	glyph := "  "
+
+	switch space.Content {
+	case Virus:
+		glyph = "◉ "
+	case Pill:
+		switch space.Linkage {
+		case Unlinked:
+			glyph = "● "
+		case Right:
+			// left half of a horizontal capsule
+			glyph = "◖█"
+		case Left:
+			// right half of a horizontal capsule
+			glyph = "█◗"
+		case Down:
+			// top half of a vertical capsule
+			glyph = "▄▄"
+		case Up:
+			// bottom half of a vertical capsule
+			glyph = "▀▀"
+		}
+	}
+
+	switch space.Color {
+	case Red:
+		return fmt.Sprintf(ansiForeground, ansiRed) + glyph
+	case Blue:
+		return fmt.Sprintf(ansiForeground, ansiBlue) + glyph
+	case Yellow:
+		return fmt.Sprintf(ansiForeground, ansiYellow) + glyph
+	case Green:
+		return fmt.Sprintf(ansiForeground, ansiGreen) + glyph
+	}
+
+	return glyph
+}
+
+func ansiBorderString(border string) string {
+	return fmt.Sprintf(ansiForeground, ansiBorder) + border + ansiReset
+}
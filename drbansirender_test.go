@@ -0,0 +1,53 @@
+package drbreakboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestANSIRender(t *testing.T) {
+	field := makeTwoChainField()
+	field.SpawnCapsule(Yellow, Blue)
+
+	var buffer bytes.Buffer
+	err := ANSIRenderer{}.Render(&buffer, field)
+	if err != nil {
+		t.Fatalf("render failed %v", err)
+	}
+	output := buffer.String()
+	t.Log("\n" + output)
+
+	lines := strings.Split(output, "\n")
+	if len(lines) != field.GetHeight()+2 {
+		t.Fatalf("rendered %v lines", len(lines))
+	}
+
+	// capsule on the top row, the left half of the horizontal pill and the viruses
+	if !strings.Contains(lines[0], "\x1b[38;5;226m◖█") || !strings.Contains(lines[0], "\x1b[38;5;39m█◗") {
+		t.Fatalf("active capsule not drawn: %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[11], "\x1b[38;5;196m◖█") {
+		t.Fatalf("linked pill not drawn: %q", lines[11])
+	}
+	if strings.Count(output, "◉") != field.GetVirusCount() {
+		t.Fatal("wrong number of viruses drawn")
+	}
+	if strings.Contains(output, ansiReverse) {
+		t.Fatal("overlay drawn without one set")
+	}
+
+	// overlay the pending clear and add a border
+	iterField, _, _ := field.EvaluateBoardIteration()
+	buffer.Reset()
+	ANSIRenderer{Overlay: iterField, Border: true}.Render(&buffer, field)
+	output = buffer.String()
+	t.Log("\n" + output)
+
+	if strings.Count(output, ansiReverse) != 4 {
+		t.Fatalf("expected 4 cleared spaces highlighted, got %v", strings.Count(output, ansiReverse))
+	}
+	if !strings.Contains(output, "└────────────────┘") {
+		t.Fatal("border floor not drawn")
+	}
+}
@@ -0,0 +1,375 @@
+package drbreakboard
+
+import (
+	"math/bits"
+)
+
+// largest board that fits in a bitboard
+const (
+	bitboardMaxWidth  = 8
+	bitboardMaxHeight = 16
+)
+
+// one bit per space, space y, x is bit y*8+x
+// rows 0 to 7 are in lo and rows 8 to 15 in hi. rows are always 8 bits
+// whatever the board width, bits past the board are never set
+type bitboard struct {
+	lo uint64
+	hi uint64
+}
+
+var (
+	bitboardColumn0 = bitboard{0x0101010101010101, 0x0101010101010101}
+	bitboardColumn7 = bitboard{0x8080808080808080, 0x8080808080808080}
+)
+
+// a board split into bitboards by content, color and linkage, kept up to
+// date by every write to the board. colors and links are indexed by
+// SpaceColor and SpaceLinkage, Uncolored and Unlinked are left empty.
+// valid is false if the board is too big for bitboards or has a space with
+// a content, color or linkage they cannot hold, evaluation then walks the
+// space slices instead
+type bitboardField struct {
+	valid    bool
+	width    int
+	height   int
+	occupied bitboard
+	viruses  bitboard
+	pills    bitboard
+	colors   [Green + 1]bitboard
+	links    [Right + 1]bitboard
+}
+
+// bitboard with only space y, x set
+func bitboardSpace(y int, x int) bitboard {
+	index := uint(y*bitboardMaxWidth + x)
+	if index < 64 {
+		return bitboard{lo: 1 << index}
+	}
+	return bitboard{hi: 1 << (index - 64)}
+}
+
+// bitboard with every space in column x set
+func bitboardColumn(x int) bitboard {
+	return bitboard{bitboardColumn0.lo << uint(x), bitboardColumn0.hi << uint(x)}
+}
+
+// bitboard with every space in row y set
+func bitboardRow(y int) bitboard {
+	index := uint(y * bitboardMaxWidth)
+	if index < 64 {
+		return bitboard{lo: 0xFF << index}
+	}
+	return bitboard{hi: 0xFF << (index - 64)}
+}
+
+func (b bitboard) or(other bitboard) bitboard {
+	return bitboard{b.lo | other.lo, b.hi | other.hi}
+}
+
+func (b bitboard) and(other bitboard) bitboard {
+	return bitboard{b.lo & other.lo, b.hi & other.hi}
+}
+
+func (b bitboard) andNot(other bitboard) bitboard {
+	return bitboard{b.lo &^ other.lo, b.hi &^ other.hi}
+}
+
+func (b bitboard) isEmpty() bool {
+	return b.lo == 0 && b.hi == 0
+}
+
+func (b bitboard) has(y int, x int) bool {
+	return !b.and(bitboardSpace(y, x)).isEmpty()
+}
+
+func (b bitboard) count() int {
+	return bits.OnesCount64(b.lo) + bits.OnesCount64(b.hi)
+}
+
+// call visit for every set space in row order
+func (b bitboard) forEach(visit func(y int, x int)) {
+	for i, half := range [2]uint64{b.lo, b.hi} {
+		for ; half != 0; half &= half - 1 {
+			index := i*64 + bits.TrailingZeros64(half)
+			visit(index/bitboardMaxWidth, index%bitboardMaxWidth)
+		}
+	}
+}
+
+// move every space one column right, spaces leaving the row are dropped
+func (b bitboard) shiftRight() bitboard {
+	return bitboard{b.lo << 1, b.hi<<1 | b.lo>>63}.andNot(bitboardColumn0)
+}
+
+// move every space one column left, spaces leaving the row are dropped
+func (b bitboard) shiftLeft() bitboard {
+	return bitboard{b.lo>>1 | b.hi<<63, b.hi >> 1}.andNot(bitboardColumn7)
+}
+
+// move every space one row down
+func (b bitboard) shiftDown() bitboard {
+	return bitboard{b.lo << 8, b.hi<<8 | b.lo>>56}
+}
+
+// move every space one row up
+func (b bitboard) shiftUp() bitboard {
+	return bitboard{b.lo>>8 | b.hi<<56, b.hi >> 8}
+}
+
+func (b bitboard) shiftUpLeft() bitboard {
+	return b.shiftUp().shiftLeft()
+}
+
+func (b bitboard) shiftUpRight() bitboard {
+	return b.shiftUp().shiftRight()
+}
+
+func (b bitboard) shiftDownLeft() bitboard {
+	return b.shiftDown().shiftLeft()
+}
+
+func (b bitboard) shiftDownRight() bitboard {
+	return b.shiftDown().shiftRight()
+}
+
+// move every space toward its linked partner
+func (b bitboard) shiftToward(linkage SpaceLinkage) bitboard {
+	switch linkage {
+	case Up:
+		return b.shiftUp()
+	case Down:
+		return b.shiftDown()
+	case Left:
+		return b.shiftLeft()
+	case Right:
+		return b.shiftRight()
+	}
+	return bitboard{}
+}
+
+// spaces that are in a run of length or more
+// forward moves a space toward the rest of its run, back away from it
+func (b bitboard) runs(length int, forward func(bitboard) bitboard, back func(bitboard) bitboard) bitboard {
+	// spaces that start a run
+	starts := b
+	shifted := b
+	for i := 1; i < length; i++ {
+		shifted = forward(shifted)
+		starts = starts.and(shifted)
+	}
+
+	// spread the starts over the rest of their run
+	runs := starts
+	for i := 1; i < length; i++ {
+		starts = back(starts)
+		runs = runs.or(starts)
+	}
+
+	return runs
+}
+
+// empty bitboards for a board size
+func newBitboardField(width int, height int) bitboardField {
+	return bitboardField{
+		valid: width > 0 && width <= bitboardMaxWidth &&
+			height > 0 && height <= bitboardMaxHeight,
+		width:  width,
+		height: height,
+	}
+}
+
+// check if a space can be held in bitboards
+func canBitboardSpace(space Space) bool {
+	return space.Content >= Empty && space.Content <= Pill &&
+		space.Color >= Uncolored && space.Color <= Green &&
+		space.Linkage >= Unlinked && space.Linkage <= Right
+}
+
+// rebuild the board's bitboards from its spaces
+// only needed where spaces are written without putSpaceAtCoordinate
+func (field *PlayField) syncBitboards() {
+	field.bitboards = newBitboardField(field.GetWidth(), field.GetHeight())
+	for y, row := range field.spaces {
+		for x, space := range row {
+			field.bitboards.setSpace(y, x, space)
+		}
+	}
+}
+
+// set space y, x in the bitboards, replacing whatever was there
+// a space the bitboards cannot hold leaves them invalid until rebuilt
+func (bitboards *bitboardField) setSpace(y int, x int, space Space) {
+	if !bitboards.valid {
+		return
+	}
+	if !canBitboardSpace(space) {
+		bitboards.valid = false
+		return
+	}
+
+	bit := bitboardSpace(y, x)
+	bitboards.occupied = bitboards.occupied.andNot(bit)
+	bitboards.viruses = bitboards.viruses.andNot(bit)
+	bitboards.pills = bitboards.pills.andNot(bit)
+	for i := range bitboards.colors {
+		bitboards.colors[i] = bitboards.colors[i].andNot(bit)
+	}
+	for i := range bitboards.links {
+		bitboards.links[i] = bitboards.links[i].andNot(bit)
+	}
+
+	if space.Color != Uncolored {
+		bitboards.colors[space.Color] = bitboards.colors[space.Color].or(bit)
+	}
+
+	if space.Content == Empty {
+		return
+	}
+
+	bitboards.occupied = bitboards.occupied.or(bit)
+	if space.Content == Virus {
+		bitboards.viruses = bitboards.viruses.or(bit)
+	} else {
+		bitboards.pills = bitboards.pills.or(bit)
+	}
+	if space.Linkage != Unlinked {
+		bitboards.links[space.Linkage] = bitboards.links[space.Linkage].or(bit)
+	}
+}
+
+// spaces resting on a fixed virus or the bottom, directly or through
+// the spaces below them and their linked partners
+func (bitboards *bitboardField) docked(looseViruses bool) bitboard {
+	docked := bitboards.occupied.and(bitboardRow(bitboards.height - 1))
+	if !looseViruses {
+		docked = docked.or(bitboards.viruses)
+	}
+
+	for {
+		// the space above a docked space and its linked partner are docked
+		next := docked.or(docked.shiftUp())
+		for linkage := Up; linkage <= Right; linkage++ {
+			next = next.or(docked.and(bitboards.links[linkage]).shiftToward(linkage))
+		}
+		next = next.and(bitboards.occupied)
+
+		if next == docked {
+			return docked
+		}
+		docked = next
+	}
+}
+
+// evaluate an iteration on the board's bitboards, which must be valid
+// gives exactly the result evaluateSlices does
+func (evaluator *Evaluator) evaluateBitboards(field *PlayField) IterationResult {
+	bitboards := &field.bitboards
+	ruleset := field.GetRuleset()
+	nextIterationField := evaluator.resetIterationField(field)
+
+	result := IterationResult{nextIterationField, NoAction, nil, 0}
+
+	// undocked pills, and viruses that are not fixed, fall before anything clears
+	loose := bitboards.pills
+	if ruleset.LooseViruses {
+		loose = loose.or(bitboards.viruses)
+	}
+	falling := loose.andNot(bitboards.docked(ruleset.LooseViruses))
+	if !falling.isEmpty() {
+		result.Next = Fall
+		markSpaces(nextIterationField, falling, Fall)
+		return result
+	}
+
+	result.Streaks = evaluator.resetStreaks()
+
+	var rowStarts, columnStarts, downStarts, upStarts, cleared bitboard
+	for color := Red; color <= Green; color++ {
+		colorBoard := bitboards.colors[color]
+		rows := colorBoard.runs(ruleset.MinMatchLength, bitboard.shiftLeft, bitboard.shiftRight)
+		columns := colorBoard.runs(ruleset.MinMatchLength, bitboard.shiftUp, bitboard.shiftDown)
+
+		// a streak starts where the space before it is not in the run
+		rowStarts = rowStarts.or(rows.andNot(rows.shiftRight()))
+		columnStarts = columnStarts.or(columns.andNot(columns.shiftDown()))
+		cleared = cleared.or(rows).or(columns)
+
+		if ruleset.Diagonals {
+			down := colorBoard.runs(ruleset.MinMatchLength, bitboard.shiftUpLeft, bitboard.shiftDownRight)
+			up := colorBoard.runs(ruleset.MinMatchLength, bitboard.shiftDownLeft, bitboard.shiftUpRight)
+
+			downStarts = downStarts.or(down.andNot(down.shiftDownRight()))
+			upStarts = upStarts.or(up.andNot(up.shiftUpRight()))
+			cleared = cleared.or(down).or(up)
+		}
+	}
+
+	if cleared.isEmpty() {
+		return result
+	}
+
+	// report streaks in the order evaluateSlices finds them, rows top to
+	// bottom, columns left to right, then each diagonal in row order
+	result.Streaks = bitboards.appendStreaks(result.Streaks, Horizontal, rowStarts)
+	result.Streaks = bitboards.appendStreaks(result.Streaks, Vertical, columnStarts)
+	result.Streaks = bitboards.appendStreaks(result.Streaks, DiagonalDown, downStarts)
+	result.Streaks = bitboards.appendStreaks(result.Streaks, DiagonalUp, upStarts)
+
+	result.Next = Clear
+	result.ClearedVirusCount = cleared.and(bitboards.viruses).count()
+	markSpaces(nextIterationField, cleared, Clear)
+
+	// keep any room the streaks grew for the next evaluation
+	evaluator.streaks = result.Streaks
+	return result
+}
+
+// add the streak at each start space, in column order for vertical
+// streaks and row order for the rest
+func (bitboards *bitboardField) appendStreaks(streaks []Streak, orientation StreakOrientation, starts bitboard) []Streak {
+	if orientation == Vertical {
+		for x := 0; x < bitboards.width; x++ {
+			column := starts.and(bitboardColumn(x))
+			column.forEach(func(y int, x int) {
+				streaks = append(streaks, bitboards.getStreak(orientation, y, x))
+			})
+		}
+		return streaks
+	}
+
+	starts.forEach(func(y int, x int) {
+		streaks = append(streaks, bitboards.getStreak(orientation, y, x))
+	})
+	return streaks
+}
+
+// get the streak starting at a space
+func (bitboards *bitboardField) getStreak(orientation StreakOrientation, y int, x int) Streak {
+	streak := Streak{Orientation: orientation, Start: Coordinate{y, x}}
+	for color := Red; color <= Green; color++ {
+		if bitboards.colors[color].has(y, x) {
+			streak.Color = color
+		}
+	}
+
+	dy, dx := orientation.getStep()
+	for y >= 0 && y < bitboards.height && x < bitboards.width && bitboards.colors[streak.Color].has(y, x) {
+		streak.Length += 1
+		if bitboards.viruses.has(y, x) {
+			streak.VirusCount += 1
+		}
+
+		y += dy
+		x += dx
+	}
+
+	return streak
+}
+
+// set every space in spaces to iteration in an iteration field
+func markSpaces(iterField [][]NextIteration, spaces bitboard, iteration NextIteration) {
+	spaces.forEach(func(y int, x int) {
+		iterField[y][x] = iteration
+	})
+}
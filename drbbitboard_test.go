@@ -0,0 +1,227 @@
+package drbreakboard
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// make a board of random spaces, linkages do not have to match up
+func makeRandomSpacesField(rng *rand.Rand, width int, height int) *PlayField {
+	field := NewPlayField(width, height)
+	for y := range field.spaces {
+		for x := range field.spaces[y] {
+			// mostly empty so there is room to fall
+			if rng.Intn(3) == 0 {
+				continue
+			}
+			field.spaces[y][x] = Space{
+				SpaceContent(rng.Intn(int(Pill) + 1)),
+				SpaceLinkage(rng.Intn(int(Right) + 1)),
+				SpaceColor(rng.Intn(int(Green) + 1)),
+			}
+		}
+	}
+	field.syncBitboards()
+	return field
+}
+
+// make a ruleset with a random match length and virus rule
+func makeRandomRuleset(rng *rand.Rand) Ruleset {
+	return Ruleset{
+		MinMatchLength: rng.Intn(4) + MinRulesetMatchLength,
+		ColorCount:     rng.Intn(MaxColorCount-MinColorCount+1) + MinColorCount,
+		Diagonals:      rng.Intn(2) == 0,
+		LooseViruses:   rng.Intn(2) == 0,
+	}
+}
+
+// make a virus level with random capsules dropped in, every step of
+// resolving it is a board that could come up in a game
+func makeRandomPlayedFields(rng *rand.Rand, width int, height int) []*PlayField {
+	field := NewPlayField(width, height)
+	field.GenerateVirusLevel(rng.Intn(MaxVirusLevel+1), rng)
+
+	for i := 0; i < width*height/3; i++ {
+		linkage := Right
+		if rng.Intn(2) == 0 {
+			linkage = Up
+		}
+		space, linkedSpace, _ := MakeLinkedPillSpaces(linkage,
+			SpaceColor(rng.Intn(3)+1), SpaceColor(rng.Intn(3)+1))
+		field.PutTwoLinkedSpacesAtCoordinate(rng.Intn(height), rng.Intn(width), space, linkedSpace)
+	}
+
+	fields := []*PlayField{field.Clone()}
+	for i := 0; i < 100; i++ {
+		step, err := field.StepBoard()
+		if err != nil || step.Kind == NoAction {
+			break
+		}
+		fields = append(fields, field.Clone())
+	}
+	return fields
+}
+
+func checkSameEvaluation(t *testing.T, field *PlayField) {
+	if !field.bitboards.valid {
+		t.Fatal("board did not fit in bitboards")
+	}
+
+	expected := NewEvaluator().evaluateSlices(field)
+	result := NewEvaluator().evaluateBitboards(field)
+	if !reflect.DeepEqual(result, expected) {
+		logBoard(t, field)
+		t.Fatalf("bitboard evaluation %+v differs from %+v", result, expected)
+	}
+}
+
+func TestBitboardEvaluationMatchesSlices(t *testing.T) {
+	rng := rand.New(rand.NewSource(21))
+
+	for i := 0; i < 2000; i++ {
+		width, height := rng.Intn(bitboardMaxWidth)+1, rng.Intn(bitboardMaxHeight)+1
+		field := makeRandomSpacesField(rng, width, height)
+		if i%2 == 0 {
+			field.ruleset = makeRandomRuleset(rng)
+		}
+		checkSameEvaluation(t, field)
+	}
+
+	for i := 0; i < 200; i++ {
+		width, height := rng.Intn(bitboardMaxWidth-3)+4, rng.Intn(bitboardMaxHeight-5)+6
+		for _, field := range makeRandomPlayedFields(rng, width, height) {
+			checkSameEvaluation(t, field)
+		}
+	}
+
+	checkSameEvaluation(t, makeTwoChainField())
+}
+
+// bitboards kept through board changes match ones rebuilt from the spaces
+func checkBitboardsInSync(t *testing.T, field *PlayField) {
+	kept := field.bitboards
+	field.syncBitboards()
+	if kept != field.bitboards {
+		logBoard(t, field)
+		t.Fatalf("kept bitboards %+v differ from rebuilt %+v", kept, field.bitboards)
+	}
+}
+
+func TestBitboardsFollowBoardChanges(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+
+	for i := 0; i < 100; i++ {
+		field := NewPlayField(DefaultWidth, DefaultHeight)
+		field.GenerateVirusLevel(rng.Intn(MaxVirusLevel+1), rng)
+		checkBitboardsInSync(t, field)
+
+		for j := 0; j < 20; j++ {
+			linkage := Right
+			if rng.Intn(2) == 0 {
+				linkage = Up
+			}
+			space, linkedSpace, _ := MakeLinkedPillSpaces(linkage, Red, Blue)
+			field.PutTwoLinkedSpacesAtCoordinate(rng.Intn(DefaultHeight), rng.Intn(DefaultWidth), space, linkedSpace)
+			field.ForcePutSingleSpaceIntoBoard(rng.Intn(DefaultHeight), rng.Intn(DefaultWidth), Space{Pill, Unlinked, Yellow})
+			checkBitboardsInSync(t, field)
+		}
+
+		for {
+			step, err := field.StepBoard()
+			if err != nil || step.Kind == NoAction {
+				break
+			}
+			checkBitboardsInSync(t, field)
+		}
+
+		field.ClearBoard()
+		checkBitboardsInSync(t, field)
+	}
+}
+
+func TestBitboardFallback(t *testing.T) {
+	if NewPlayField(9, 16).bitboards.valid || NewPlayField(8, 17).bitboards.valid {
+		t.Fatal("board too big for bitboards has them")
+	}
+
+	field := NewPlayField(8, 16)
+	field.ForcePutSingleSpaceIntoBoard(0, 0, Space{Virus, Unlinked, SpaceColor(9)})
+	if field.bitboards.valid {
+		t.Fatal("unknown color kept bitboards")
+	}
+
+	// an unknown color still evaluates, on slices
+	for x := 2; x < 6; x++ {
+		field.ForcePutSingleSpaceIntoBoard(15, x, Space{Virus, Unlinked, Red})
+	}
+	if result := field.EvaluateBoardIterationStreaks(); result.Next != Clear || len(result.Streaks) != 1 {
+		t.Fatalf("board with an unknown color evaluated to %+v", result)
+	}
+
+	// big boards still evaluate
+	wide := NewPlayField(12, 4)
+	for x := 2; x < 7; x++ {
+		wide.ForcePutSingleSpaceIntoBoard(3, x, Space{Virus, Unlinked, Blue})
+	}
+	result := wide.EvaluateBoardIterationStreaks()
+	if result.Next != Clear || len(result.Streaks) != 1 || result.Streaks[0].Length != 5 {
+		t.Fatalf("wide board evaluated to %+v", result)
+	}
+}
+
+func makeBenchmarkFields() []*PlayField {
+	rng := rand.New(rand.NewSource(99))
+	fields := make([]*PlayField, 0)
+	for len(fields) < 256 {
+		fields = append(fields, makeRandomPlayedFields(rng, DefaultWidth, DefaultHeight)...)
+	}
+	return fields
+}
+
+func reportEvaluationsPerSecond(b *testing.B, start time.Time) {
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "evals/s")
+}
+
+func BenchmarkEvaluateSlices(b *testing.B) {
+	fields := makeBenchmarkFields()
+	evaluator := NewEvaluator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+
+	for i := 0; i < b.N; i++ {
+		evaluator.evaluateSlices(fields[i%len(fields)])
+	}
+
+	reportEvaluationsPerSecond(b, start)
+}
+
+func BenchmarkEvaluateBitboards(b *testing.B) {
+	fields := makeBenchmarkFields()
+	evaluator := NewEvaluator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+
+	for i := 0; i < b.N; i++ {
+		evaluator.Evaluate(fields[i%len(fields)])
+	}
+
+	reportEvaluationsPerSecond(b, start)
+}
+
+// a fresh evaluator every call, like EvaluateBoardIterationStreaks
+func BenchmarkEvaluateBoardIterationStreaks(b *testing.B) {
+	fields := makeBenchmarkFields()
+	b.ReportAllocs()
+	b.ResetTimer()
+	start := time.Now()
+
+	for i := 0; i < b.N; i++ {
+		fields[i%len(fields)].EvaluateBoardIterationStreaks()
+	}
+
+	reportEvaluationsPerSecond(b, start)
+}
@@ -0,0 +1,270 @@
+package drbreakboard
+
+import (
+	"errors"
+)
+
+// returned when a new capsule cannot spawn because the spawn spaces are filled
+var ErrSpawnBlocked = errors.New("capsule spawn is blocked")
+
+// the player controlled capsule that is still in flight
+// y and x are the coordinate of the bottom left half of the capsule and
+// linkage points from that half to its partner, Right when horizontal
+// and Up when vertical
+type ActiveCapsule struct {
+	y           int
+	x           int
+	linkage     SpaceLinkage
+	coordColor  SpaceColor
+	linkedColor SpaceColor
+}
+
+// get the coordinate of the bottom left half of the capsule
+func (capsule ActiveCapsule) GetCoordinate() (int, int) {
+	return capsule.y, capsule.x
+}
+
+// get the coordinate of the half linked to the bottom left half
+func (capsule ActiveCapsule) GetLinkedCoordinate() (int, int) {
+	linkedY, linkedX, _ := GetLinkedCoordinate(capsule.y, capsule.x, capsule.linkage)
+	return linkedY, linkedX
+}
+
+func (capsule ActiveCapsule) GetLinkage() SpaceLinkage {
+	return capsule.linkage
+}
+
+// get the spaces the capsule writes into the board when locked,
+// the bottom left half first and its linked partner second
+func (capsule ActiveCapsule) GetSpaces() (Space, Space) {
+	coordSpace, linkedSpace, _ := MakeLinkedPillSpaces(capsule.linkage, capsule.coordColor, capsule.linkedColor)
+	return coordSpace, linkedSpace
+}
+
+// get the coordinate new capsules spawn at
+// the left half sits in the top row just left of center
+func (field *PlayField) GetCapsuleSpawnCoordinate() (int, int) {
+	return 0, (field.GetWidth() - 1) / 2
+}
+
+// spawn a horizontal capsule at the top center of the board
+// errors if a capsule is already active or the spawn spaces are blocked
+func (field *PlayField) SpawnCapsule(leftColor SpaceColor, rightColor SpaceColor) error {
+	if field.capsule != nil {
+		return errors.New("capsule is already active")
+	}
+
+	if leftColor == Uncolored || rightColor == Uncolored {
+		return errors.New("capsule halves must have a color")
+	}
+
+	if field.IsSpawnBlocked() {
+		return ErrSpawnBlocked
+	}
+
+	y, x := field.GetCapsuleSpawnCoordinate()
+	field.capsule = &ActiveCapsule{y, x, Right, leftColor, rightColor}
+	return nil
+}
+
+// check if a new capsule could not spawn, which loses the game
+func (field *PlayField) IsSpawnBlocked() bool {
+	y, x := field.GetCapsuleSpawnCoordinate()
+	return field.checkCapsulePlacement(ActiveCapsule{y, x, Right, Red, Red}) != nil
+}
+
+// get the active capsule, false if there is none
+func (field *PlayField) GetActiveCapsule() (ActiveCapsule, bool) {
+	if field.capsule == nil {
+		return ActiveCapsule{}, false
+	}
+
+	return *field.capsule, true
+}
+
+func (field *PlayField) HasActiveCapsule() bool {
+	return field.capsule != nil
+}
+
+// check if the capsule is resting on something and cannot drop further
+func (field *PlayField) IsCapsuleGrounded() bool {
+	if field.capsule == nil {
+		return false
+	}
+
+	dropped := *field.capsule
+	dropped.y += 1
+	return field.checkCapsulePlacement(dropped) != nil
+}
+
+// get the space at a coordinate as it should be drawn, with the active
+// capsule's halves in place of the board spaces under it
+func (field *PlayField) GetDrawnSpaceAtCoordinate(y int, x int) (Space, error) {
+	space, err := field.GetSpaceAtCoordinate(y, x)
+	if err != nil || field.capsule == nil {
+		return space, err
+	}
+
+	linkedY, linkedX := field.capsule.GetLinkedCoordinate()
+	coordSpace, linkedSpace := field.capsule.GetSpaces()
+	if y == field.capsule.y && x == field.capsule.x {
+		return coordSpace, nil
+	}
+	if y == linkedY && x == linkedX {
+		return linkedSpace, nil
+	}
+
+	return space, nil
+}
+
+// move the capsule one space left
+// returns false if the capsule was blocked
+func (field *PlayField) MoveCapsuleLeft() (bool, error) {
+	return field.moveCapsule(0, -1)
+}
+
+// move the capsule one space right
+// returns false if the capsule was blocked
+func (field *PlayField) MoveCapsuleRight() (bool, error) {
+	return field.moveCapsule(0, 1)
+}
+
+// move the capsule one space down
+// returns false if the capsule was blocked, the capsule is not locked
+// so callers decide when a blocked capsule should lock
+func (field *PlayField) SoftDropCapsule() (bool, error) {
+	return field.moveCapsule(1, 0)
+}
+
+// rotate the capsule clockwise
+// horizontal to vertical puts the left half on top, vertical to horizontal
+// puts the top half on the right. returns false if the rotation was blocked
+func (field *PlayField) RotateCapsuleClockwise() (bool, error) {
+	return field.rotateCapsule(true)
+}
+
+// rotate the capsule counter-clockwise
+// horizontal to vertical puts the right half on top, vertical to horizontal
+// puts the top half on the left. returns false if the rotation was blocked
+func (field *PlayField) RotateCapsuleCounterClockwise() (bool, error) {
+	return field.rotateCapsule(false)
+}
+
+// apply a player action to the capsule
+// returns false if the action was blocked or does not move the capsule
+func (field *PlayField) applyCapsuleAction(action Action) (bool, error) {
+	switch action {
+	case ActionMoveLeft:
+		return field.MoveCapsuleLeft()
+	case ActionMoveRight:
+		return field.MoveCapsuleRight()
+	case ActionRotateClockwise:
+		return field.RotateCapsuleClockwise()
+	case ActionRotateCounterClockwise:
+		return field.RotateCapsuleCounterClockwise()
+	case ActionSoftDrop:
+		return field.SoftDropCapsule()
+	}
+
+	return false, nil
+}
+
+// write the capsule halves into the board at the capsule position
+// and clear the active capsule
+func (field *PlayField) LockCapsule() error {
+	if field.capsule == nil {
+		return errors.New("no active capsule to lock")
+	}
+
+	coordSpace, linkedSpace, err := MakeLinkedPillSpaces(field.capsule.linkage,
+		field.capsule.coordColor, field.capsule.linkedColor)
+	if err != nil {
+		return err
+	}
+
+	err = field.PutTwoLinkedSpacesAtCoordinate(field.capsule.y, field.capsule.x, coordSpace, linkedSpace)
+	if err != nil {
+		return err
+	}
+
+	field.capsule = nil
+	return nil
+}
+
+// move the capsule by the given offset if both halves land in empty spaces
+func (field *PlayField) moveCapsule(dy int, dx int) (bool, error) {
+	if field.capsule == nil {
+		return false, errors.New("no active capsule to move")
+	}
+
+	moved := *field.capsule
+	moved.y += dy
+	moved.x += dx
+
+	if field.checkCapsulePlacement(moved) != nil {
+		// blocked, leave capsule where it is
+		return false, nil
+	}
+
+	field.capsule = &moved
+	return true, nil
+}
+
+// check both halves of a capsule are in bounds and empty
+func (field *PlayField) checkCapsulePlacement(capsule ActiveCapsule) error {
+	if err := field.checkCoordinateInBoundsAndEmpty(capsule.y, capsule.x); err != nil {
+		return err
+	}
+
+	linkedY, linkedX, err := GetLinkedCoordinate(capsule.y, capsule.x, capsule.linkage)
+	if err != nil {
+		return err
+	}
+
+	return field.checkCoordinateInBoundsAndEmpty(linkedY, linkedX)
+}
+
+// rotate the capsule around its bottom left half
+// the linkage cycles between Right and Up and the colors swap so the halves
+// keep their clockwise order. a vertical capsule that cannot turn horizontal
+// in place is kicked one space left like the original game
+func (field *PlayField) rotateCapsule(clockwise bool) (bool, error) {
+	if field.capsule == nil {
+		return false, errors.New("no active capsule to rotate")
+	}
+
+	rotated := *field.capsule
+
+	if rotated.linkage == Right {
+		// horizontal to vertical, bottom left half stays in place
+		rotated.linkage = Up
+		if clockwise {
+			// left half goes on top, right half becomes the bottom
+			rotated.coordColor, rotated.linkedColor = rotated.linkedColor, rotated.coordColor
+		}
+	} else {
+		// vertical to horizontal, bottom half stays in place
+		rotated.linkage = Right
+		if !clockwise {
+			// top half goes left, bottom half becomes the right
+			rotated.coordColor, rotated.linkedColor = rotated.linkedColor, rotated.coordColor
+		}
+	}
+
+	if field.checkCapsulePlacement(rotated) == nil {
+		field.capsule = &rotated
+		return true, nil
+	}
+
+	// only a capsule turning horizontal can kick, try one space left
+	if rotated.linkage == Right {
+		rotated.x -= 1
+		if field.checkCapsulePlacement(rotated) == nil {
+			field.capsule = &rotated
+			return true, nil
+		}
+	}
+
+	// blocked, leave capsule as it was
+	return false, nil
+}
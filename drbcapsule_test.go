@@ -0,0 +1,224 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+func TestCapsuleSpawn(t *testing.T) {
+	field := NewPlayField(8, 16)
+
+	err := field.SpawnCapsule(Red, Blue)
+	if err != nil {
+		t.Fatalf("spawn failed %v", err)
+	}
+
+	capsule, ok := field.GetActiveCapsule()
+	if !ok {
+		t.Fatal("no active capsule after spawn")
+	}
+
+	y, x := capsule.GetCoordinate()
+	linkedY, linkedX := capsule.GetLinkedCoordinate()
+	if y != 0 || x != 3 || linkedY != 0 || linkedX != 4 {
+		t.Fatalf("capsule spawned at %v,%v and %v,%v", y, x, linkedY, linkedX)
+	}
+
+	err = field.SpawnCapsule(Red, Blue)
+	if err == nil {
+		t.Fatal("second capsule spawned while one was active")
+	}
+
+	// blocked spawn
+	field = NewPlayField(8, 16)
+	virus, _ := MakeVirus(Yellow)
+	field.PutSpaceAtCoordinateIfEmpty(0, 4, virus)
+	if !field.IsSpawnBlocked() {
+		t.Fatal("spawn not reported blocked")
+	}
+	err = field.SpawnCapsule(Red, Blue)
+	if err != ErrSpawnBlocked {
+		t.Fatalf("capsule spawned into a blocked space, %v", err)
+	}
+	if field.HasActiveCapsule() {
+		t.Fatal("blocked spawn left an active capsule")
+	}
+}
+
+func TestCapsuleMovement(t *testing.T) {
+	field := NewPlayField(8, 16)
+	field.SpawnCapsule(Red, Blue)
+
+	// move to the left wall
+	for i := 0; i < 3; i++ {
+		moved, err := field.MoveCapsuleLeft()
+		if err != nil || !moved {
+			t.Fatalf("move left %v failed, %v %v", i, moved, err)
+		}
+	}
+	moved, _ := field.MoveCapsuleLeft()
+	if moved {
+		t.Fatal("capsule moved through left wall")
+	}
+
+	// move to the right wall
+	for i := 0; i < 6; i++ {
+		moved, err := field.MoveCapsuleRight()
+		if err != nil || !moved {
+			t.Fatalf("move right %v failed, %v %v", i, moved, err)
+		}
+	}
+	moved, _ = field.MoveCapsuleRight()
+	if moved {
+		t.Fatal("capsule moved through right wall")
+	}
+
+	// block with a virus under the right half
+	virus, _ := MakeVirus(Yellow)
+	field.PutSpaceAtCoordinateIfEmpty(5, 7, virus)
+	for i := 0; i < 4; i++ {
+		moved, err := field.SoftDropCapsule()
+		if err != nil || !moved {
+			t.Fatalf("drop %v failed, %v %v", i, moved, err)
+		}
+	}
+	moved, _ = field.SoftDropCapsule()
+	if moved {
+		t.Fatal("capsule dropped through virus")
+	}
+
+	capsule, _ := field.GetActiveCapsule()
+	y, x := capsule.GetCoordinate()
+	if y != 4 || x != 6 {
+		t.Fatalf("capsule ended at %v,%v", y, x)
+	}
+}
+
+func TestCapsuleLock(t *testing.T) {
+	field := NewPlayField(8, 16)
+
+	err := field.LockCapsule()
+	if err == nil {
+		t.Fatal("locked with no active capsule")
+	}
+
+	field.SpawnCapsule(Red, Blue)
+	for {
+		moved, err := field.SoftDropCapsule()
+		if err != nil {
+			t.Fatalf("drop errored %v", err)
+		}
+		if !moved {
+			break
+		}
+	}
+
+	err = field.LockCapsule()
+	if err != nil {
+		t.Fatalf("lock failed %v", err)
+	}
+
+	if field.HasActiveCapsule() {
+		t.Fatal("capsule still active after lock")
+	}
+
+	bottomRow := field.GetBottomRowIndex()
+	left, _ := field.GetSpaceAtCoordinate(bottomRow, 3)
+	right, _ := field.GetSpaceAtCoordinate(bottomRow, 4)
+	if left != (Space{Pill, Right, Red}) || right != (Space{Pill, Left, Blue}) {
+		t.Fatalf("locked capsule wrote %v and %v", left, right)
+	}
+
+	_, nextIter, _ := field.EvaluateBoardIteration()
+	if nextIter != NoAction {
+		t.Fatal("locked capsule on bottom should not change")
+	}
+}
+
+func TestCapsuleRotation(t *testing.T) {
+	field := NewPlayField(8, 16)
+	field.SpawnCapsule(Red, Blue)
+
+	// no room above the top row to turn vertical
+	rotated, err := field.RotateCapsuleClockwise()
+	if err != nil || rotated {
+		t.Fatalf("rotated out of the top of the board, %v %v", rotated, err)
+	}
+
+	field.SoftDropCapsule()
+
+	// clockwise puts the left half on top
+	rotated, _ = field.RotateCapsuleClockwise()
+	if !rotated {
+		t.Fatal("clockwise rotation failed")
+	}
+	capsule, _ := field.GetActiveCapsule()
+	bottom, top := capsule.GetSpaces()
+	y, x := capsule.GetCoordinate()
+	if y != 1 || x != 3 || capsule.GetLinkage() != Up {
+		t.Fatalf("vertical capsule at %v,%v linkage %v", y, x, capsule.GetLinkage())
+	}
+	if top != (Space{Pill, Down, Red}) || bottom != (Space{Pill, Up, Blue}) {
+		t.Fatalf("clockwise colors wrong, top %v bottom %v", top, bottom)
+	}
+
+	// clockwise again puts the top half on the right
+	field.RotateCapsuleClockwise()
+	capsule, _ = field.GetActiveCapsule()
+	left, right := capsule.GetSpaces()
+	if left != (Space{Pill, Right, Blue}) || right != (Space{Pill, Left, Red}) {
+		t.Fatalf("second clockwise colors wrong, left %v right %v", left, right)
+	}
+
+	// counter-clockwise undoes clockwise
+	field.RotateCapsuleCounterClockwise()
+	field.RotateCapsuleCounterClockwise()
+	capsule, _ = field.GetActiveCapsule()
+	left, right = capsule.GetSpaces()
+	if left != (Space{Pill, Right, Red}) || right != (Space{Pill, Left, Blue}) {
+		t.Fatalf("counter-clockwise colors wrong, left %v right %v", left, right)
+	}
+}
+
+func TestCapsuleWallKick(t *testing.T) {
+	field := NewPlayField(8, 16)
+	field.SpawnCapsule(Red, Blue)
+	field.SoftDropCapsule()
+	field.RotateCapsuleClockwise()
+
+	// push vertical capsule against the right wall
+	for {
+		moved, _ := field.MoveCapsuleRight()
+		if !moved {
+			break
+		}
+	}
+
+	rotated, _ := field.RotateCapsuleClockwise()
+	if !rotated {
+		t.Fatal("rotation against wall did not kick")
+	}
+	capsule, _ := field.GetActiveCapsule()
+	y, x := capsule.GetCoordinate()
+	if y != 1 || x != 6 {
+		t.Fatalf("kicked capsule at %v,%v", y, x)
+	}
+
+	// vertical capsule boxed in on both sides cannot rotate
+	field = NewPlayField(8, 16)
+	virus, _ := MakeVirus(Yellow)
+	field.PutSpaceAtCoordinateIfEmpty(2, 2, virus)
+	field.PutSpaceAtCoordinateIfEmpty(2, 4, virus)
+	field.SpawnCapsule(Red, Blue)
+	field.SoftDropCapsule()
+	field.RotateCapsuleClockwise()
+	field.SoftDropCapsule()
+
+	rotated, _ = field.RotateCapsuleClockwise()
+	if rotated {
+		t.Fatal("boxed in capsule rotated")
+	}
+	capsule, _ = field.GetActiveCapsule()
+	if capsule.GetLinkage() != Up {
+		t.Fatal("blocked rotation changed the capsule")
+	}
+}
@@ -0,0 +1,123 @@
+package drbreakboard
+
+// the result of a single board iteration
+type ChainStep struct {
+	// what the iteration did, NoAction if the board was already stable
+	Kind NextIteration
+	// spaces removed by a Clear step
+	Cleared []Coordinate
+	// spaces that fell one row in a Fall step, given before the fall
+	Moved []Coordinate
+	// color of each streak removed by a Clear step
+	StreakColors []SpaceColor
+	// every streak removed by a Clear step
+	Streaks []Streak
+	// number of viruses removed by a Clear step
+	VirusesCleared int
+	// number of Clear steps in the chain up to and including this step
+	ChainDepth int
+}
+
+// the steps taken to resolve a board until nothing clears or falls
+type ChainReport struct {
+	Steps []ChainStep
+	// total number of Clear steps in the chain
+	ChainDepth int
+	// total number of viruses removed by the chain
+	VirusesCleared int
+}
+
+// get the colors of every streak cleared in the chain in order
+func (report ChainReport) GetStreakColors() []SpaceColor {
+	colors := make([]SpaceColor, 0)
+	for _, step := range report.Steps {
+		colors = append(colors, step.StreakColors...)
+	}
+	return colors
+}
+
+// add a step to the report, setting its chain depth
+func (report *ChainReport) addStep(step ChainStep) ChainStep {
+	if step.Kind == Clear {
+		report.ChainDepth += 1
+	}
+	report.VirusesCleared += step.VirusesCleared
+	step.ChainDepth = report.ChainDepth
+	report.Steps = append(report.Steps, step)
+	return step
+}
+
+// iterate the board once like IterateBoard and report what happened
+// a single step does not know its place in a chain so ChainDepth is
+// 1 for a Clear and 0 otherwise
+func (field *PlayField) StepBoard() (ChainStep, error) {
+	return field.stepBoard(NewEvaluator())
+}
+
+// StepBoard evaluating with an evaluator kept by the caller
+func (field *PlayField) stepBoard(evaluator *Evaluator) (ChainStep, error) {
+	result := evaluator.Evaluate(field)
+
+	step := makeChainStep(result)
+	if result.Next == Clear {
+		step.ChainDepth = 1
+	}
+
+	err := field.applyIteration(result.Field, result.Next)
+	if err != nil {
+		return step, err
+	}
+
+	return step, field.debugValidate()
+}
+
+// iterate the board until no more clears or falls happen
+// error means something is semantically wrong with the board,
+// the report holds the steps taken before the error
+func (field *PlayField) ResolveUntilStable() (ChainReport, error) {
+	return field.resolveUntilStable(NewEvaluator())
+}
+
+// ResolveUntilStable evaluating with an evaluator kept by the caller
+func (field *PlayField) resolveUntilStable(evaluator *Evaluator) (ChainReport, error) {
+	report := ChainReport{}
+
+	for {
+		step, err := field.stepBoard(evaluator)
+		if err != nil {
+			return report, err
+		}
+
+		if step.Kind == NoAction {
+			return report, nil
+		}
+
+		report.addStep(step)
+	}
+}
+
+// build a chain step from an evaluated iteration
+func makeChainStep(result IterationResult) ChainStep {
+	step := ChainStep{Kind: result.Next}
+
+	if result.Next == NoAction {
+		return step
+	}
+
+	// the evaluator reuses its streaks, keep a copy
+	step.StreakColors = result.GetStreakColors()
+	step.Streaks = append([]Streak(nil), result.Streaks...)
+	step.VirusesCleared = result.ClearedVirusCount
+	for y, row := range result.Field {
+		for x, iter := range row {
+			switch iter {
+			case Clear:
+				step.Cleared = append(step.Cleared, Coordinate{y, x})
+			case Fall:
+				step.Moved = append(step.Moved, Coordinate{y, x})
+			}
+		}
+	}
+
+	return step
+}
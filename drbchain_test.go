@@ -0,0 +1,81 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+// build a board where a blue column clear drops a red half into a red column
+func makeTwoChainField() *PlayField {
+	field := NewPlayField(8, 16)
+
+	redVirus, _ := MakeVirus(Red)
+	blueVirus, _ := MakeVirus(Blue)
+	yellowVirus, _ := MakeVirus(Yellow)
+
+	field.PutSpaceAtCoordinateIfEmpty(13, 0, redVirus)
+	field.PutSpaceAtCoordinateIfEmpty(14, 0, redVirus)
+	field.PutSpaceAtCoordinateIfEmpty(15, 0, redVirus)
+	field.PutSpaceAtCoordinateIfEmpty(12, 1, blueVirus)
+	field.PutSpaceAtCoordinateIfEmpty(13, 1, blueVirus)
+	field.PutSpaceAtCoordinateIfEmpty(14, 1, blueVirus)
+	field.PutSpaceAtCoordinateIfEmpty(15, 1, yellowVirus)
+
+	space, linkedSpace, _ := MakeLinkedPillSpaces(Right, Red, Blue)
+	field.PutTwoLinkedSpacesAtCoordinate(11, 0, space, linkedSpace)
+
+	return field
+}
+
+func TestResolveUntilStable(t *testing.T) {
+	field := makeTwoChainField()
+	logBoard(t, field)
+
+	report, err := field.ResolveUntilStable()
+	if err != nil {
+		t.Fatalf("resolve errored %v", err)
+	}
+	logBoard(t, field)
+
+	if report.ChainDepth != 2 {
+		t.Fatalf("chain depth was %v", report.ChainDepth)
+	}
+
+	if len(report.Steps) != 3 {
+		t.Fatalf("expected clear, fall, clear but got %v steps", len(report.Steps))
+	}
+
+	expectedKinds := []NextIteration{Clear, Fall, Clear}
+	expectedDepths := []int{1, 1, 2}
+	for i, step := range report.Steps {
+		if step.Kind != expectedKinds[i] {
+			t.Fatalf("step %v kind was %v", i, step.Kind)
+		}
+		if step.ChainDepth != expectedDepths[i] {
+			t.Fatalf("step %v depth was %v", i, step.ChainDepth)
+		}
+	}
+
+	if len(report.Steps[0].Cleared) != 4 || len(report.Steps[2].Cleared) != 4 {
+		t.Fatal("clear steps should each remove 4 spaces")
+	}
+
+	moved := report.Steps[1].Moved
+	if len(moved) != 1 || moved[0].GetY() != 11 || moved[0].GetX() != 0 {
+		t.Fatalf("fall step moved %v", moved)
+	}
+
+	colors := report.GetStreakColors()
+	if len(colors) != 2 || colors[0] != Blue || colors[1] != Red {
+		t.Fatalf("streak colors were %v", colors)
+	}
+
+	if field.GetVirusCount() != 1 {
+		t.Fatalf("expected only the yellow virus left, have %v", field.GetVirusCount())
+	}
+
+	// resolving a stable board does nothing
+	report, err = field.ResolveUntilStable()
+	if err != nil || len(report.Steps) != 0 || report.ChainDepth != 0 {
+		t.Fatalf("stable board resolved to %v, %v", report, err)
+	}
+}
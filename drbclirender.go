@@ -1,37 +1,57 @@
 package drbreakboard
 
 import (
-	"fmt"
+	"io"
+	"os"
 	"strings"
 )
 
+// draws a board to a writer
+type Renderer interface {
+	Render(w io.Writer, field *PlayField) error
+}
+
+// draws the board as raw three letter space codes
+// this is the text format ParsePlayField reads
+type RawRenderer struct{}
+
+func (renderer RawRenderer) Render(w io.Writer, field *PlayField) error {
+	_, err := io.WriteString(w, FormatPlayField(field))
+	return err
+}
+
+// draw the board to stdout with the raw renderer
 func DrawBoard(field *PlayField) {
-	for y := 0; y < field.GetHeight(); y++ {
-		for x := 0; x < field.GetWidth(); x++ {
-			space, _ := field.GetSpaceAtCoordinate(y, x)
-			fmt.Print(generateRawSpaceString(space))
-			fmt.Print(" ")
-		}
-		fmt.Println("")
-	}
-	fmt.Println("")
+	RawRenderer{}.Render(os.Stdout, field)
 }
 
+// draw a next iteration field to stdout
 func DrawNextIteration(field [][]NextIteration) {
+	RenderNextIteration(os.Stdout, field)
+}
+
+// write a next iteration field with one letter per space,
+// X for no action, C for clear and F for fall
+func RenderNextIteration(w io.Writer, field [][]NextIteration) error {
+	var sb strings.Builder
+
 	for _, row := range field {
 		for _, space := range row {
 			switch space {
 			case NoAction:
-				fmt.Print("X")
+				sb.WriteString("X")
 			case Clear:
-				fmt.Print("C")
+				sb.WriteString("C")
 			case Fall:
-				fmt.Print("F")
+				sb.WriteString("F")
 			}
 		}
-		fmt.Println("")
+		sb.WriteString("\n")
 	}
-	fmt.Println("")
+	sb.WriteString("\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
 }
 
 func generateRawSpaceString(space Space) string {
@@ -55,6 +75,8 @@ func generateRawSpaceString(space Space) string {
 		sb.WriteString("B")
 	case Yellow:
 		sb.WriteString("Y")
+	case Green:
+		sb.WriteString("G")
 	}
 
 	switch space.Linkage {
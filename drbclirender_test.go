@@ -0,0 +1,61 @@
+package drbreakboard
+
+import (
+	"bytes"
+	"testing"
+)
+
+// renderers draw into the test log so they only show with -v
+func logBoard(t *testing.T, field *PlayField) {
+	t.Helper()
+	var buffer bytes.Buffer
+	RawRenderer{}.Render(&buffer, field)
+	t.Log("\n" + buffer.String())
+}
+
+func logNextIteration(t *testing.T, iterField [][]NextIteration) {
+	t.Helper()
+	var buffer bytes.Buffer
+	RenderNextIteration(&buffer, iterField)
+	t.Log("\n" + buffer.String())
+}
+
+func TestRawRender(t *testing.T) {
+	field := NewPlayField(4, 3)
+	virus, _ := MakeVirus(Yellow)
+	space, linkedSpace, _ := MakeLinkedPillSpaces(Right, Red, Blue)
+	field.PutSpaceAtCoordinateIfEmpty(2, 3, virus)
+	field.PutTwoLinkedSpacesAtCoordinate(2, 0, space, linkedSpace)
+	field.PutSpaceAtCoordinateIfEmpty(0, 1, Space{Pill, Unlinked, Blue})
+
+	expected := "XXX PBX XXX XXX \n" +
+		"XXX XXX XXX XXX \n" +
+		"PRR PBL XXX VYX \n" +
+		"\n"
+
+	var renderer Renderer = RawRenderer{}
+	var buffer bytes.Buffer
+	if err := renderer.Render(&buffer, field); err != nil {
+		t.Fatalf("render failed %v", err)
+	}
+	if buffer.String() != expected {
+		t.Fatalf("raw render was\n%v", buffer.String())
+	}
+
+	iterField, _, _ := field.EvaluateBoardIteration()
+	buffer.Reset()
+	RenderNextIteration(&buffer, iterField)
+	if buffer.String() != "XFXX\nXXXX\nXXXX\n\n" {
+		t.Fatalf("next iteration render was\n%v", buffer.String())
+	}
+}
+
+func TestRenderersAreRenderers(t *testing.T) {
+	renderers := []Renderer{RawRenderer{}, ANSIRenderer{}}
+	for _, renderer := range renderers {
+		var buffer bytes.Buffer
+		if err := renderer.Render(&buffer, NewPlayField(8, 16)); err != nil || buffer.Len() == 0 {
+			t.Fatalf("renderer %T failed %v", renderer, err)
+		}
+	}
+}
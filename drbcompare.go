@@ -0,0 +1,121 @@
+package drbreakboard
+
+import (
+	"errors"
+)
+
+// tags keep the key inputs for board size, settled spaces and capsule
+// halves apart from each other
+const (
+	zobristSizeTag    uint64 = 1 << 62
+	zobristCapsuleTag uint64 = 1 << 61
+)
+
+// return a deep copy of the board and its active capsule
+func (field *PlayField) Clone() *PlayField {
+	clone := NewPlayField(field.GetWidth(), field.GetHeight())
+	clone.ruleset = field.ruleset
+	for y, row := range field.spaces {
+		copy(clone.spaces[y], row)
+	}
+	clone.bitboards = field.bitboards
+
+	if field.capsule != nil {
+		capsule := *field.capsule
+		clone.capsule = &capsule
+	}
+
+	return clone
+}
+
+// check if two boards have the same size, ruleset, spaces and active capsule
+func (field *PlayField) Equal(other *PlayField) bool {
+	if field.GetWidth() != other.GetWidth() || field.GetHeight() != other.GetHeight() {
+		return false
+	}
+
+	if field.GetRuleset() != other.GetRuleset() {
+		return false
+	}
+
+	if (field.capsule == nil) != (other.capsule == nil) {
+		return false
+	}
+	if field.capsule != nil && *field.capsule != *other.capsule {
+		return false
+	}
+
+	for y, row := range field.spaces {
+		for x, space := range row {
+			if space != other.spaces[y][x] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// list the coordinates whose drawn space differs between two boards,
+// in row order. a moved capsule shows up as the spaces it left and entered
+// boards must be the same size
+func (field *PlayField) Diff(other *PlayField) ([]Coordinate, error) {
+	if field.GetWidth() != other.GetWidth() || field.GetHeight() != other.GetHeight() {
+		return nil, errors.New("cannot diff boards of different sizes")
+	}
+
+	changed := make([]Coordinate, 0)
+	for y := 0; y < field.GetHeight(); y++ {
+		for x := 0; x < field.GetWidth(); x++ {
+			space, _ := field.GetDrawnSpaceAtCoordinate(y, x)
+			otherSpace, _ := other.GetDrawnSpaceAtCoordinate(y, x)
+			if space != otherSpace {
+				changed = append(changed, Coordinate{y, x})
+			}
+		}
+	}
+
+	return changed, nil
+}
+
+// stable 64 bit zobrist hash of the board size, spaces and active capsule
+// keys are computed rather than random so hashes match between processes
+// and peers. empty spaces add nothing, so a space can be updated by xoring
+// out its old key and xoring in its new one
+func (field *PlayField) Hash() uint64 {
+	hash := splitMix64(zobristSizeTag | uint64(field.GetWidth())<<32 | uint64(field.GetHeight()))
+
+	for y, row := range field.spaces {
+		for x, space := range row {
+			hash ^= zobristKey(0, uint64(y*len(row)+x), space)
+		}
+	}
+
+	if field.capsule != nil {
+		linkedY, linkedX := field.capsule.GetLinkedCoordinate()
+		coordSpace, linkedSpace := field.capsule.GetSpaces()
+		width := field.GetWidth()
+		hash ^= zobristKey(zobristCapsuleTag, uint64(field.capsule.y*width+field.capsule.x), coordSpace)
+		hash ^= zobristKey(zobristCapsuleTag, uint64(linkedY*width+linkedX), linkedSpace)
+	}
+
+	return hash
+}
+
+// key for a space at a position index
+func zobristKey(tag uint64, index uint64, space Space) uint64 {
+	if space == (Space{}) {
+		return 0
+	}
+
+	return splitMix64(tag | index<<24 | uint64(uint8(space.Content))<<16 |
+		uint64(uint8(space.Linkage))<<8 | uint64(uint8(space.Color)))
+}
+
+// splitmix64 finalizer, spreads every input bit over the output
+func splitMix64(value uint64) uint64 {
+	value += 0x9E3779B97F4A7C15
+	value = (value ^ value>>30) * 0xBF58476D1CE4E5B9
+	value = (value ^ value>>27) * 0x94D049BB133111EB
+	return value ^ value>>31
+}
@@ -0,0 +1,102 @@
+package drbreakboard
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestCloneEqual(t *testing.T) {
+	field := makeEncodingField()
+	clone := field.Clone()
+
+	if !field.Equal(clone) || !clone.Equal(field) {
+		t.Fatal("clone not equal to original")
+	}
+	if clone.Hash() != field.Hash() {
+		t.Fatal("clone hash differs")
+	}
+
+	// changes to the clone do not reach the original
+	clone.MoveCapsuleLeft()
+	if field.Equal(clone) {
+		t.Fatal("moved capsule still equal")
+	}
+	clone = field.Clone()
+	clone.ForcePutSingleSpaceIntoBoard(0, 0, Space{Pill, Unlinked, Red})
+	if space, _ := field.GetSpaceAtCoordinate(0, 0); space.Content != Empty {
+		t.Fatal("clone shares spaces with original")
+	}
+	if field.Equal(clone) {
+		t.Fatal("changed space still equal")
+	}
+
+	if field.Equal(NewPlayField(8, 17)) {
+		t.Fatal("different sized boards equal")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	field := NewPlayField(8, 16)
+	field.SpawnCapsule(Red, Blue)
+	moved := field.Clone()
+	moved.SoftDropCapsule()
+	moved.ForcePutSingleSpaceIntoBoard(15, 7, Space{Virus, Unlinked, Yellow})
+
+	changed, err := field.Diff(moved)
+	if err != nil {
+		t.Fatalf("diff failed %v", err)
+	}
+
+	// capsule left row 0, entered row 1, plus the new virus
+	expected := []Coordinate{{0, 3}, {0, 4}, {1, 3}, {1, 4}, {15, 7}}
+	if len(changed) != len(expected) {
+		t.Fatalf("diff found %v", changed)
+	}
+	for i := range expected {
+		if changed[i] != expected[i] {
+			t.Fatalf("diff found %v", changed)
+		}
+	}
+
+	if changed, _ = field.Diff(field.Clone()); len(changed) != 0 {
+		t.Fatal("diff of equal boards is not empty")
+	}
+	if _, err = field.Diff(NewPlayField(4, 4)); err == nil {
+		t.Fatal("diff of different sized boards worked")
+	}
+}
+
+func TestHash(t *testing.T) {
+	// hashes are stable across runs, this value must not change
+	field := makeTwoChainField()
+	if hash := field.Hash(); hash != 0xb3cfb3b5aa7b2a60 {
+		t.Fatalf("hash changed to %#x", hash)
+	}
+
+	empty := NewPlayField(8, 16)
+	if empty.Hash() == NewPlayField(16, 8).Hash() {
+		t.Fatal("board size not hashed")
+	}
+
+	// a settled pill and a capsule half in the same space hash differently
+	settled := empty.Clone()
+	settled.PutTwoLinkedSpacesAtCoordinate(0, 3, Space{Pill, Right, Red}, Space{Pill, Left, Blue})
+	empty.SpawnCapsule(Red, Blue)
+	if empty.Hash() == settled.Hash() {
+		t.Fatal("capsule hashed like settled pills")
+	}
+
+	// random single space changes all change the hash
+	rng := rand.New(rand.NewSource(5))
+	for i := 0; i < 500; i++ {
+		changed := field.Clone()
+		space := Space{SpaceContent(rng.Intn(3)), Unlinked, SpaceColor(rng.Intn(4))}
+		changed.ForcePutSingleSpaceIntoBoard(rng.Intn(16), rng.Intn(8), space)
+		if changed.Equal(field) {
+			continue
+		}
+		if changed.Hash() == field.Hash() {
+			t.Fatalf("changed board %v has the same hash", space)
+		}
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !drbdebug
+
+package drbreakboard
+
+// validate the board after every iteration
+const debugValidation = false
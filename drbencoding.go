@@ -0,0 +1,380 @@
+package drbreakboard
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// version written at the start of the binary board encoding
+const binaryEncodingVersion = 1
+
+// length of the binary header, version, width, height and ruleset
+const binaryHeaderLength = 8
+
+// ruleset flag bits in the binary header
+const (
+	binaryDiagonalsFlag    = 0x01
+	binaryLooseVirusesFlag = 0x02
+)
+
+// first line of the text encoding, the board's ruleset
+const rulesetLineFormat = "ruleset match=%d colors=%d diagonals=%t loose=%t"
+
+// bit layout of a space packed into a byte
+const (
+	contentBits  = 0x03
+	colorShift   = 2
+	colorBits    = 0x07
+	linkageShift = 5
+	linkageBits  = 0x07
+)
+
+// json form of a board, each row is the text format row of space codes
+// boards without a ruleset decode with classic rules
+type playFieldJSON struct {
+	Width   int          `json:"width"`
+	Height  int          `json:"height"`
+	Ruleset Ruleset      `json:"ruleset"`
+	Rows    []string     `json:"rows"`
+	Capsule *capsuleJSON `json:"capsule,omitempty"`
+}
+
+// json form of an active capsule, the bottom left half's coordinate and
+// the spaces it locks as
+type capsuleJSON struct {
+	Y      int      `json:"y"`
+	X      int      `json:"x"`
+	Spaces [2]Space `json:"spaces"`
+}
+
+// encode a space as its three letter code
+func (space Space) MarshalText() ([]byte, error) {
+	return []byte(generateRawSpaceString(space)), nil
+}
+
+func (space *Space) UnmarshalText(text []byte) error {
+	parsed, err := parseRawSpaceString(string(text))
+	if err != nil {
+		return err
+	}
+
+	*space = parsed
+	return nil
+}
+
+// encode a space as a single byte
+func (space Space) MarshalBinary() ([]byte, error) {
+	packed, err := packSpace(space)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte{packed}, nil
+}
+
+func (space *Space) UnmarshalBinary(data []byte) error {
+	if len(data) != 1 {
+		return errors.New("space must be a single byte")
+	}
+
+	unpacked, err := unpackSpace(data[0])
+	if err != nil {
+		return err
+	}
+
+	*space = unpacked
+	return nil
+}
+
+// encode the board as a ruleset line followed by the text format written
+// by FormatPlayField. the active capsule is not part of the text format
+func (field *PlayField) MarshalText() ([]byte, error) {
+	ruleset := field.GetRuleset()
+	line := fmt.Sprintf(rulesetLineFormat, ruleset.MinMatchLength, ruleset.ColorCount,
+		ruleset.Diagonals, ruleset.LooseViruses)
+	return []byte(line + "\n" + FormatPlayField(field)), nil
+}
+
+// decode a board written by MarshalText
+// text without a ruleset line decodes with classic rules
+func (field *PlayField) UnmarshalText(text []byte) error {
+	board := strings.TrimLeft(string(text), " \t\r\n")
+
+	var ruleset Ruleset
+	if strings.HasPrefix(board, "ruleset") {
+		line, rest, _ := strings.Cut(board, "\n")
+		_, err := fmt.Sscanf(line, rulesetLineFormat, &ruleset.MinMatchLength, &ruleset.ColorCount,
+			&ruleset.Diagonals, &ruleset.LooseViruses)
+		if err != nil {
+			return fmt.Errorf("bad ruleset line: %w", err)
+		}
+		board = rest
+	}
+
+	parsed, err := ParsePlayField(board)
+	if err != nil {
+		return err
+	}
+
+	return field.replaceDecoded(parsed, ruleset)
+}
+
+// encode the board and active capsule as json
+func (field *PlayField) MarshalJSON() ([]byte, error) {
+	encoded := playFieldJSON{
+		Width:   field.GetWidth(),
+		Height:  field.GetHeight(),
+		Ruleset: field.GetRuleset(),
+		Rows:    make([]string, field.GetHeight()),
+	}
+
+	for y, row := range field.spaces {
+		codes := make([]string, len(row))
+		for x, space := range row {
+			codes[x] = generateRawSpaceString(space)
+		}
+		encoded.Rows[y] = strings.Join(codes, " ")
+	}
+
+	if capsule, ok := field.GetActiveCapsule(); ok {
+		coordSpace, linkedSpace := capsule.GetSpaces()
+		encoded.Capsule = &capsuleJSON{capsule.y, capsule.x, [2]Space{coordSpace, linkedSpace}}
+	}
+
+	return json.Marshal(encoded)
+}
+
+func (field *PlayField) UnmarshalJSON(data []byte) error {
+	var encoded playFieldJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	parsed, err := ParsePlayField(strings.Join(encoded.Rows, "\n"))
+	if err != nil {
+		return err
+	}
+
+	if parsed.GetWidth() != encoded.Width || parsed.GetHeight() != encoded.Height {
+		return errors.New("board rows do not match width and height")
+	}
+
+	if encoded.Capsule != nil {
+		err = parsed.restoreCapsule(encoded.Capsule.Y, encoded.Capsule.X,
+			encoded.Capsule.Spaces[0], encoded.Capsule.Spaces[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return field.replaceDecoded(parsed, encoded.Ruleset)
+}
+
+// encode the board and active capsule in a compact binary form
+// a version byte, big endian uint16 width and height, the ruleset's match
+// length and color count bytes and a flags byte, one byte per space
+// row by row, then a capsule flag byte followed by the capsule's
+// uint16 y and x and its two spaces when set
+func (field *PlayField) MarshalBinary() ([]byte, error) {
+	width := field.GetWidth()
+	height := field.GetHeight()
+	if width > 0xFFFF || height > 0xFFFF {
+		return nil, errors.New("board too large for binary encoding")
+	}
+
+	ruleset := field.GetRuleset()
+	if ruleset.MinMatchLength > 0xFF {
+		return nil, errors.New("match length too long for binary encoding")
+	}
+
+	data := make([]byte, binaryHeaderLength, binaryHeaderLength+width*height+7)
+	data[0] = binaryEncodingVersion
+	binary.BigEndian.PutUint16(data[1:], uint16(width))
+	binary.BigEndian.PutUint16(data[3:], uint16(height))
+	data[5] = byte(ruleset.MinMatchLength)
+	data[6] = byte(ruleset.ColorCount)
+	if ruleset.Diagonals {
+		data[7] |= binaryDiagonalsFlag
+	}
+	if ruleset.LooseViruses {
+		data[7] |= binaryLooseVirusesFlag
+	}
+
+	for _, row := range field.spaces {
+		for _, space := range row {
+			packed, err := packSpace(space)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, packed)
+		}
+	}
+
+	capsule, ok := field.GetActiveCapsule()
+	if !ok {
+		return append(data, 0), nil
+	}
+
+	coordSpace, linkedSpace := capsule.GetSpaces()
+	packedCoord, _ := packSpace(coordSpace)
+	packedLinked, _ := packSpace(linkedSpace)
+
+	data = append(data, 1)
+	data = binary.BigEndian.AppendUint16(data, uint16(capsule.y))
+	data = binary.BigEndian.AppendUint16(data, uint16(capsule.x))
+	return append(data, packedCoord, packedLinked), nil
+}
+
+func (field *PlayField) UnmarshalBinary(data []byte) error {
+	if len(data) < binaryHeaderLength {
+		return errors.New("binary board too short")
+	}
+
+	if data[0] != binaryEncodingVersion {
+		return errors.New("unknown binary board version")
+	}
+
+	width := int(binary.BigEndian.Uint16(data[1:]))
+	height := int(binary.BigEndian.Uint16(data[3:]))
+	if width == 0 || height == 0 {
+		return errors.New("binary board has no spaces")
+	}
+
+	ruleset := Ruleset{
+		MinMatchLength: int(data[5]),
+		ColorCount:     int(data[6]),
+		Diagonals:      data[7]&binaryDiagonalsFlag != 0,
+		LooseViruses:   data[7]&binaryLooseVirusesFlag != 0,
+	}
+
+	data = data[binaryHeaderLength:]
+	if len(data) < width*height+1 {
+		return errors.New("binary board too short")
+	}
+
+	parsed := NewPlayField(width, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			space, err := unpackSpace(data[y*width+x])
+			if err != nil {
+				return err
+			}
+			parsed.putSpaceAtCoordinate(y, x, space)
+		}
+	}
+
+	if err := violationsError(parsed.Validate()); err != nil {
+		return err
+	}
+
+	data = data[width*height:]
+	switch {
+	case data[0] == 0 && len(data) == 1:
+		// no capsule
+	case data[0] == 1 && len(data) == 7:
+		coordSpace, err := unpackSpace(data[5])
+		if err != nil {
+			return err
+		}
+		linkedSpace, err := unpackSpace(data[6])
+		if err != nil {
+			return err
+		}
+		err = parsed.restoreCapsule(int(binary.BigEndian.Uint16(data[1:])),
+			int(binary.BigEndian.Uint16(data[3:])), coordSpace, linkedSpace)
+		if err != nil {
+			return err
+		}
+	default:
+		return errors.New("binary board has a bad capsule section")
+	}
+
+	return field.replaceDecoded(parsed, ruleset)
+}
+
+// replace the board with a decoded one playing by a ruleset
+// errors if the ruleset cannot be played or the board has colors it does
+// not play with
+func (field *PlayField) replaceDecoded(parsed *PlayField, ruleset Ruleset) error {
+	if err := ruleset.check(); err != nil {
+		return err
+	}
+	parsed.ruleset = ruleset
+
+	var inPlay [Green + 1]bool
+	inPlay[Uncolored] = true
+	for _, color := range ruleset.GetColors() {
+		inPlay[color] = true
+	}
+
+	for y, row := range parsed.spaces {
+		for x, space := range row {
+			if !inPlay[space.Color] {
+				return fmt.Errorf("space %v,%v has a color the ruleset does not play with", y, x)
+			}
+		}
+	}
+
+	if capsule, ok := parsed.GetActiveCapsule(); ok {
+		if !inPlay[capsule.coordColor] || !inPlay[capsule.linkedColor] {
+			return errors.New("capsule has a color the ruleset does not play with")
+		}
+	}
+
+	*field = *parsed
+	return nil
+}
+
+// set the active capsule from its bottom left coordinate and spaces
+func (field *PlayField) restoreCapsule(y int, x int, coordSpace Space, linkedSpace Space) error {
+	if coordSpace.Linkage != Right && coordSpace.Linkage != Up {
+		return errors.New("capsule must link right or up from its bottom left half")
+	}
+
+	if coordSpace.Content != Pill || linkedSpace.Content != Pill ||
+		linkedSpace.Linkage != getOpposingLinkage(coordSpace.Linkage) {
+		return errors.New("capsule spaces are not a linked pill")
+	}
+
+	if coordSpace.Color == Uncolored || linkedSpace.Color == Uncolored {
+		return errors.New("capsule halves must have a color")
+	}
+
+	capsule := ActiveCapsule{y, x, coordSpace.Linkage, coordSpace.Color, linkedSpace.Color}
+	if err := field.checkCapsulePlacement(capsule); err != nil {
+		return err
+	}
+
+	field.capsule = &capsule
+	return nil
+}
+
+// pack a space into a byte, content in the low two bits,
+// then three bits of color and three bits of linkage
+func packSpace(space Space) (byte, error) {
+	if space.Content < Empty || space.Content > Pill ||
+		space.Color < Uncolored || space.Color > Green ||
+		space.Linkage < Unlinked || space.Linkage > Right {
+		return 0, errors.New("space has an unknown content, color or linkage")
+	}
+
+	return byte(space.Content) | byte(space.Color)<<colorShift | byte(space.Linkage)<<linkageShift, nil
+}
+
+func unpackSpace(packed byte) (Space, error) {
+	space := Space{
+		Content: SpaceContent(packed & contentBits),
+		Color:   SpaceColor(packed >> colorShift & colorBits),
+		Linkage: SpaceLinkage(packed >> linkageShift & linkageBits),
+	}
+
+	// reject values that do not exist
+	if _, err := packSpace(space); err != nil {
+		return Space{}, err
+	}
+
+	return space, nil
+}
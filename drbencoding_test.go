@@ -0,0 +1,170 @@
+package drbreakboard
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// make a board with a bit of everything and an active capsule
+func makeEncodingField() *PlayField {
+	field := makeTwoChainField()
+	field.SpawnCapsule(Yellow, Blue)
+	field.SoftDropCapsule()
+	field.RotateCapsuleClockwise()
+	return field
+}
+
+func checkSameField(t *testing.T, field *PlayField, decoded *PlayField) {
+	if FormatPlayField(field) != FormatPlayField(decoded) {
+		t.Fatal("decoded board spaces differ")
+	}
+
+	capsule, ok := field.GetActiveCapsule()
+	decodedCapsule, decodedOk := decoded.GetActiveCapsule()
+	if ok != decodedOk || capsule != decodedCapsule {
+		t.Fatalf("decoded capsule %v differs from %v", decodedCapsule, capsule)
+	}
+}
+
+func TestSpaceEncoding(t *testing.T) {
+	for content := Empty; content <= Pill; content++ {
+		for color := Uncolored; color <= Green; color++ {
+			for linkage := Unlinked; linkage <= Right; linkage++ {
+				space := Space{content, linkage, color}
+
+				data, err := space.MarshalBinary()
+				if err != nil || len(data) != 1 {
+					t.Fatalf("space %v binary encoded to %v, %v", space, data, err)
+				}
+				var decoded Space
+				if err = decoded.UnmarshalBinary(data); err != nil || decoded != space {
+					t.Fatalf("space %v binary decoded to %v, %v", space, decoded, err)
+				}
+
+				text, _ := space.MarshalText()
+				if err = decoded.UnmarshalText(text); err != nil || decoded != space {
+					t.Fatalf("space %v text decoded to %v, %v", space, decoded, err)
+				}
+			}
+		}
+	}
+
+	var decoded Space
+	if decoded.UnmarshalBinary([]byte{0xFF}) == nil {
+		t.Fatal("bad space byte decoded")
+	}
+}
+
+func TestPlayFieldJSON(t *testing.T) {
+	field := makeEncodingField()
+
+	data, err := json.Marshal(field)
+	if err != nil {
+		t.Fatalf("json marshal failed %v", err)
+	}
+
+	decoded := new(PlayField)
+	if err = json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("json unmarshal failed %v", err)
+	}
+	checkSameField(t, field, decoded)
+
+	bad := []string{
+		`{"width":2,"height":1,"rows":["XXX XXX XXX"]}`,
+		`{"width":2,"height":1,"rows":["PRR XXX"]}`,
+		`{"width":2,"height":1,"rows":["XXX XXX"],"capsule":{"y":0,"x":0,"spaces":["PRR","PBR"]}}`,
+		`{"width":2,"height":1,"rows":["XXX XXX"],"capsule":{"y":0,"x":1,"spaces":["PRR","PBL"]}}`,
+	}
+	for _, text := range bad {
+		if json.Unmarshal([]byte(text), new(PlayField)) == nil {
+			t.Fatalf("bad json decoded: %v", text)
+		}
+	}
+}
+
+func TestPlayFieldBinary(t *testing.T) {
+	for _, field := range []*PlayField{makeEncodingField(), makeTwoChainField()} {
+		data, err := field.MarshalBinary()
+		if err != nil {
+			t.Fatalf("binary marshal failed %v", err)
+		}
+
+		decoded := new(PlayField)
+		if err = decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("binary unmarshal failed %v", err)
+		}
+		checkSameField(t, field, decoded)
+
+		if decoded.UnmarshalBinary(data[:len(data)-1]) == nil {
+			t.Fatal("truncated binary board decoded")
+		}
+	}
+}
+
+func TestPlayFieldText(t *testing.T) {
+	field := makeTwoChainField()
+
+	text, _ := field.MarshalText()
+	decoded := new(PlayField)
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatalf("text unmarshal failed %v", err)
+	}
+	checkSameField(t, field, decoded)
+}
+
+func TestPlayFieldEncodingRuleset(t *testing.T) {
+	ruleset := Ruleset{5, 4, true, true}
+	field, _ := NewPlayFieldWithRuleset(8, 16, ruleset)
+	field.PutSpaceAtCoordinateIfEmpty(15, 0, Space{Virus, Unlinked, Green})
+	field.SpawnCapsule(Green, Red)
+
+	text, _ := field.MarshalText()
+	jsonData, _ := json.Marshal(field)
+	binaryData, _ := field.MarshalBinary()
+
+	decoders := map[string]func(*PlayField) error{
+		"text":   func(decoded *PlayField) error { return decoded.UnmarshalText(text) },
+		"json":   func(decoded *PlayField) error { return json.Unmarshal(jsonData, decoded) },
+		"binary": func(decoded *PlayField) error { return decoded.UnmarshalBinary(binaryData) },
+	}
+	for name, decode := range decoders {
+		decoded := new(PlayField)
+		if err := decode(decoded); err != nil {
+			t.Fatalf("%v decode failed %v", name, err)
+		}
+		if decoded.GetRuleset() != ruleset {
+			t.Fatalf("%v decoded ruleset %+v", name, decoded.GetRuleset())
+		}
+	}
+
+	// boards without a ruleset play classic rules
+	decoded := new(PlayField)
+	if err := decoded.UnmarshalText([]byte(FormatPlayField(makeTwoChainField()))); err != nil ||
+		decoded.GetRuleset() != ClassicRuleset {
+		t.Fatalf("text without a ruleset decoded to %+v, %v", decoded.GetRuleset(), err)
+	}
+
+	// colors past the color count are rejected, two colors are yellow and red
+	bad := map[string]func(*PlayField) error{
+		"text": func(decoded *PlayField) error {
+			return decoded.UnmarshalText([]byte("ruleset match=4 colors=2 diagonals=false loose=false\nXXX VBX\n"))
+		},
+		"json": func(decoded *PlayField) error {
+			return json.Unmarshal([]byte(`{"width":2,"height":1,"ruleset":{"minMatchLength":4,"colorCount":2},`+
+				`"rows":["XXX XXX"],"capsule":{"y":0,"x":0,"spaces":["PYR","PBL"]}}`), decoded)
+		},
+		"binary": func(decoded *PlayField) error {
+			corrupt := append([]byte(nil), binaryData...)
+			corrupt[6] = 3
+			return decoded.UnmarshalBinary(corrupt)
+		},
+		"ruleset": func(decoded *PlayField) error {
+			return decoded.UnmarshalText([]byte("ruleset match=2 colors=3 diagonals=false loose=false\nXXX\n"))
+		},
+	}
+	for name, decode := range bad {
+		if decode(new(PlayField)) == nil {
+			t.Fatalf("bad %v board decoded", name)
+		}
+	}
+}
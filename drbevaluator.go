@@ -0,0 +1,85 @@
+package drbreakboard
+
+// evaluates board iterations into buffers it keeps between calls
+// once its buffers have grown to fit the boards it sees, evaluating
+// allocates nothing. the slices in a result belong to the evaluator and
+// are overwritten by the next Evaluate, copy them to keep them.
+// an evaluator is not safe for concurrent use
+type Evaluator struct {
+	iterField [][]NextIteration
+	streaks   []Streak
+	docked    [][]bool
+	queue     []Coordinate
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{}
+}
+
+// see what the next move and space states will be on iteration
+// same result as field.EvaluateBoardIterationStreaks
+func (evaluator *Evaluator) Evaluate(field *PlayField) IterationResult {
+	if field.bitboards.valid {
+		return evaluator.evaluateBitboards(field)
+	}
+
+	return evaluator.evaluateSlices(field)
+}
+
+// get an all NoAction iteration field sized for the board
+func (evaluator *Evaluator) resetIterationField(field *PlayField) [][]NextIteration {
+	evaluator.iterField = resizeGrid(evaluator.iterField, field)
+	for _, row := range evaluator.iterField {
+		for x := range row {
+			row[x] = NoAction
+		}
+	}
+	return evaluator.iterField
+}
+
+// get an all undocked field sized for the board
+func (evaluator *Evaluator) resetDockedField(field *PlayField) [][]bool {
+	evaluator.docked = resizeGrid(evaluator.docked, field)
+	for _, row := range evaluator.docked {
+		for x := range row {
+			row[x] = false
+		}
+	}
+	return evaluator.docked
+}
+
+// get an empty streak list, never nil so results match a fresh evaluation
+func (evaluator *Evaluator) resetStreaks() []Streak {
+	if evaluator.streaks == nil {
+		evaluator.streaks = make([]Streak, 0, 4)
+	}
+	return evaluator.streaks[:0]
+}
+
+// reslice a grid to the board size, only allocating if it is too small
+// a new grid's rows share one backing array
+func resizeGrid[T any](grid [][]T, field *PlayField) [][]T {
+	height, width := field.GetHeight(), field.GetWidth()
+
+	if cap(grid) >= height {
+		grid = grid[:height]
+		fits := true
+		for _, row := range grid {
+			fits = fits && cap(row) >= width
+		}
+
+		if fits {
+			for y := range grid {
+				grid[y] = grid[y][:width]
+			}
+			return grid
+		}
+	}
+
+	spaces := make([]T, width*height)
+	grid = make([][]T, height)
+	for y := range grid {
+		grid[y] = spaces[y*width : (y+1)*width : (y+1)*width]
+	}
+	return grid
+}
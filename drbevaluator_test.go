@@ -0,0 +1,46 @@
+package drbreakboard
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestEvaluatorMatchesFreshEvaluation(t *testing.T) {
+	rng := rand.New(rand.NewSource(22))
+	evaluator := NewEvaluator()
+
+	// one evaluator across boards of changing sizes, big ones use slices
+	for i := 0; i < 500; i++ {
+		width, height := rng.Intn(12)+1, rng.Intn(20)+1
+		field := makeRandomSpacesField(rng, width, height)
+
+		result := evaluator.Evaluate(field)
+		expected := field.EvaluateBoardIterationStreaks()
+		if !reflect.DeepEqual(result, expected) {
+			t.Fatalf("reused evaluator gave %+v for %+v", result, expected)
+		}
+	}
+}
+
+func TestEvaluatorAllocations(t *testing.T) {
+	fields := makeBenchmarkFields()
+	big := make([]*PlayField, 0)
+	rng := rand.New(rand.NewSource(3))
+	for len(big) < 64 {
+		big = append(big, makeRandomPlayedFields(rng, 12, 20)...)
+	}
+
+	for name, boards := range map[string][]*PlayField{"bitboards": fields, "slices": big} {
+		evaluator := NewEvaluator()
+		// the warm up run grows the buffers to fit every board
+		allocs := testing.AllocsPerRun(10, func() {
+			for _, field := range boards {
+				evaluator.Evaluate(field)
+			}
+		})
+		if allocs != 0 {
+			t.Fatalf("evaluating on %v allocated %v times per run", name, allocs)
+		}
+	}
+}
@@ -0,0 +1,431 @@
+package drbreakboard
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"github.com/rs/zerolog/log"
+)
+
+type Action int
+type GamePhase int
+type GameState int
+
+const (
+	ActionNone Action = iota
+	ActionMoveLeft
+	ActionMoveRight
+	ActionSoftDrop
+	ActionRotateClockwise
+	ActionRotateCounterClockwise
+)
+
+const (
+	// player is controlling a capsule
+	PhaseCapsule GamePhase = iota
+	// cleared streaks are showing before they are removed
+	PhaseClear
+	// pieces are waiting to fall a row
+	PhaseFall
+	// game was won or lost and no longer changes
+	PhaseOver
+)
+
+const (
+	Playing GameState = iota
+	// every virus was cleared
+	Won
+	// a new capsule could not spawn
+	Lost
+)
+
+// default board size
+const (
+	DefaultWidth  = 8
+	DefaultHeight = 16
+)
+
+// names used when writing actions as text
+var actionNames = []string{"none", "left", "right", "drop", "cw", "ccw"}
+
+func (action Action) String() string {
+	if action < ActionNone || int(action) >= len(actionNames) {
+		return fmt.Sprintf("Action(%d)", int(action))
+	}
+	return actionNames[action]
+}
+
+func (action Action) MarshalText() ([]byte, error) {
+	if action < ActionNone || int(action) >= len(actionNames) {
+		return nil, errors.New("unknown action")
+	}
+	return []byte(actionNames[action]), nil
+}
+
+func (action *Action) UnmarshalText(text []byte) error {
+	for i, name := range actionNames {
+		if name == string(text) {
+			*action = Action(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown action %q", text)
+}
+
+// frame counts for the game simulation
+type GameTiming struct {
+	// frames between the capsule dropping a row on its own
+	GravityFrames int
+	// frames a capsule rests on something before it locks
+	LockDelayFrames int
+	// frames cleared streaks show before they are removed
+	ClearFrames int
+	// frames between each row of falling pieces
+	FallFrames int
+}
+
+// get the classic timing for a speed setting
+func ClassicTiming(speed Speed) GameTiming {
+	gravity := 39
+	switch speed {
+	case Med:
+		gravity = 26
+	case Hi:
+		gravity = 15
+	}
+
+	return GameTiming{
+		GravityFrames:   gravity,
+		LockDelayFrames: gravity,
+		ClearFrames:     20,
+		FallFrames:      8,
+	}
+}
+
+// settings to start a game with
+// a zero Width or Height uses the default board size
+type GameConfig struct {
+	Width      int            `json:"width"`
+	Height     int            `json:"height"`
+	Level      int            `json:"level"`
+	Speed      Speed          `json:"speed"`
+	Seed       int64          `json:"seed"`
+	Randomizer RandomizerKind `json:"randomizer"`
+	// zero fields play by ClassicRuleset
+	Ruleset Ruleset `json:"ruleset"`
+}
+
+// single player game simulation advanced one frame at a time with Tick
+// the game owns the board, capsule sequence and score
+type Game struct {
+	field    *PlayField
+	config   GameConfig
+	timing   GameTiming
+	sequence *CapsuleSequence
+	scorer   *Scorer
+
+	phase        GamePhase
+	state        GameState
+	frame        uint64
+	capsuleCount int
+
+	// frame counters for the current phase
+	gravityFrames int
+	lockFrames    int
+	phaseFrames   int
+
+	// evaluated iteration waiting for its pause to finish
+	pending IterationResult
+	// chain of the capsule being resolved and of the last resolved capsule
+	chain     ChainReport
+	lastChain ChainReport
+}
+
+// start a game, generating the virus level and capsule sequence from the seed
+func NewGame(config GameConfig) (*Game, error) {
+	randomizer, err := NewCapsuleRandomizerWithRuleset(config.Randomizer, config.Seed, config.Ruleset)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewGameWithSequence(config, NewCapsuleSequence(randomizer))
+}
+
+// start a game drawing capsules from a sequence shared with other games
+// the virus level is still generated from the config seed
+func NewGameWithSequence(config GameConfig, sequence *CapsuleSequence) (*Game, error) {
+	if config.Width == 0 {
+		config.Width = DefaultWidth
+	}
+
+	if config.Height == 0 {
+		config.Height = DefaultHeight
+	}
+
+	if config.Width < 2 || config.Height < 1 {
+		return nil, errors.New("board is too small for a capsule")
+	}
+
+	config.Ruleset = config.Ruleset.orClassic()
+
+	field, err := NewPlayFieldWithRuleset(config.Width, config.Height, config.Ruleset)
+	if err != nil {
+		return nil, err
+	}
+
+	err = field.GenerateVirusLevel(config.Level, rand.New(rand.NewSource(config.Seed)))
+	if err != nil {
+		return nil, err
+	}
+
+	return newGame(field, config, sequence)
+}
+
+// start a game on an already populated board
+func newGame(field *PlayField, config GameConfig, sequence *CapsuleSequence) (*Game, error) {
+	game := &Game{
+		field:    field,
+		config:   config,
+		timing:   ClassicTiming(config.Speed),
+		sequence: sequence,
+		scorer:   NewScorer(config.Speed, config.Level),
+		phase:    PhaseCapsule,
+		state:    Playing,
+	}
+
+	err := game.spawnNextCapsule()
+	if err != nil {
+		return nil, err
+	}
+
+	return game, nil
+}
+
+func (game *Game) GetField() *PlayField {
+	return game.field
+}
+
+func (game *Game) GetConfig() GameConfig {
+	return game.config
+}
+
+func (game *Game) GetPhase() GamePhase {
+	return game.phase
+}
+
+// get whether the game is still going, won or lost
+func (game *Game) GetState() GameState {
+	return game.state
+}
+
+func (game *Game) IsOver() bool {
+	return game.state != Playing
+}
+
+// get the number of frames simulated
+func (game *Game) GetFrame() uint64 {
+	return game.frame
+}
+
+func (game *Game) GetScorer() *Scorer {
+	return game.scorer
+}
+
+func (game *Game) GetTiming() GameTiming {
+	return game.timing
+}
+
+// replace the frame timing, takes effect on the next count
+func (game *Game) SetTiming(timing GameTiming) {
+	game.timing = timing
+}
+
+// get the number of capsules spawned so far
+func (game *Game) GetCapsuleCount() int {
+	return game.capsuleCount
+}
+
+// get the colors of the capsule that spawns after the current one
+func (game *Game) GetNextCapsule() CapsuleColors {
+	colors, _ := game.sequence.GetCapsule(game.capsuleCount)
+	return colors
+}
+
+// get the iteration waiting to be applied in PhaseClear and PhaseFall
+func (game *Game) GetPendingIteration() (IterationResult, bool) {
+	if game.phase != PhaseClear && game.phase != PhaseFall {
+		return IterationResult{}, false
+	}
+
+	return game.pending, true
+}
+
+// get the chain caused by the most recently resolved capsule
+func (game *Game) GetLastChain() ChainReport {
+	return game.lastChain
+}
+
+// advance the game one frame applying the player actions in order
+// actions are ignored while the board is resolving and a finished game
+// no longer advances. error means the game cannot continue
+func (game *Game) Tick(actions ...Action) error {
+	if game.IsOver() {
+		return nil
+	}
+
+	game.frame += 1
+
+	switch game.phase {
+	case PhaseCapsule:
+		return game.tickCapsule(actions)
+	case PhaseClear, PhaseFall:
+		return game.tickResolve()
+	}
+
+	return nil
+}
+
+// apply player actions and gravity to the capsule
+func (game *Game) tickCapsule(actions []Action) error {
+	for _, action := range actions {
+		locked, err := game.applyAction(action)
+		if err != nil {
+			return err
+		}
+
+		if locked {
+			return game.lockCapsule()
+		}
+	}
+
+	if game.field.IsCapsuleGrounded() {
+		// resting on something, count down to lock
+		game.gravityFrames = 0
+		game.lockFrames += 1
+		if game.lockFrames >= game.timing.LockDelayFrames {
+			return game.lockCapsule()
+		}
+		return nil
+	}
+
+	game.lockFrames = 0
+	game.gravityFrames += 1
+	if game.gravityFrames >= game.timing.GravityFrames {
+		game.gravityFrames = 0
+		_, err := game.field.SoftDropCapsule()
+		return err
+	}
+
+	return nil
+}
+
+// apply a single player action to the capsule
+// returns true if the action locked the capsule
+func (game *Game) applyAction(action Action) (bool, error) {
+	moved, err := game.field.applyCapsuleAction(action)
+	if action != ActionSoftDrop {
+		return false, err
+	}
+
+	// soft drop on something locks right away
+	game.gravityFrames = 0
+	return !moved && err == nil, err
+}
+
+// lock the capsule and start resolving the board
+func (game *Game) lockCapsule() error {
+	log.Debug().Msg("locking capsule")
+	err := game.field.LockCapsule()
+	if err != nil {
+		return err
+	}
+
+	game.chain = ChainReport{}
+	return game.evaluateNext()
+}
+
+// count down the current pause and apply the pending iteration when done
+func (game *Game) tickResolve() error {
+	game.phaseFrames -= 1
+	if game.phaseFrames > 0 {
+		return nil
+	}
+
+	err := game.field.applyIteration(game.pending.Field, game.pending.Next)
+	if err != nil {
+		return err
+	}
+
+	err = game.field.debugValidate()
+	if err != nil {
+		return err
+	}
+
+	step := game.chain.addStep(makeChainStep(game.pending))
+	game.scorer.ScoreChainStep(step)
+
+	return game.evaluateNext()
+}
+
+// evaluate the board and pause before the next iteration,
+// spawning the next capsule once the board is stable
+func (game *Game) evaluateNext() error {
+	game.pending = game.field.EvaluateBoardIterationStreaks()
+
+	switch game.pending.Next {
+	case Clear:
+		game.phase = PhaseClear
+		game.phaseFrames = game.timing.ClearFrames
+		return nil
+	case Fall:
+		game.phase = PhaseFall
+		game.phaseFrames = game.timing.FallFrames
+		return nil
+	}
+
+	// board is stable, the drop is over
+	game.scorer.EndDrop()
+	game.lastChain = game.chain
+	game.chain = ChainReport{}
+	game.pending = IterationResult{}
+
+	if game.field.GetVirusCount() == 0 {
+		log.Debug().Msg("last virus cleared, game won")
+		game.endGame(Won)
+		return nil
+	}
+
+	game.phase = PhaseCapsule
+	return game.spawnNextCapsule()
+}
+
+// finish the game with a result
+func (game *Game) endGame(state GameState) {
+	game.state = state
+	game.phase = PhaseOver
+}
+
+// spawn the next capsule in the sequence
+func (game *Game) spawnNextCapsule() error {
+	colors, err := game.sequence.GetCapsule(game.capsuleCount)
+	if err != nil {
+		return err
+	}
+
+	game.gravityFrames = 0
+	game.lockFrames = 0
+
+	err = game.field.SpawnCapsule(colors.Left, colors.Right)
+	if err == ErrSpawnBlocked {
+		log.Debug().Msg("capsule spawn blocked, game lost")
+		game.endGame(Lost)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	game.capsuleCount += 1
+	return nil
+}
@@ -0,0 +1,269 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+// randomizer that always gives the same capsule
+type fixedRandomizer struct {
+	colors CapsuleColors
+}
+
+func (randomizer fixedRandomizer) NextCapsule() CapsuleColors {
+	return randomizer.colors
+}
+
+func TestGameGravity(t *testing.T) {
+	game, err := NewGame(GameConfig{Level: 0, Speed: Med, Seed: 1})
+	if err != nil {
+		t.Fatalf("new game failed %v", err)
+	}
+
+	gravity := game.GetTiming().GravityFrames
+	for i := 0; i < gravity-1; i++ {
+		game.Tick()
+	}
+	capsule, _ := game.GetField().GetActiveCapsule()
+	if y, _ := capsule.GetCoordinate(); y != 0 {
+		t.Fatalf("capsule fell early to row %v", y)
+	}
+
+	game.Tick()
+	capsule, _ = game.GetField().GetActiveCapsule()
+	if y, _ := capsule.GetCoordinate(); y != 1 {
+		t.Fatalf("capsule at row %v after gravity", y)
+	}
+
+	// soft drop moves right away and resets gravity
+	game.Tick(ActionSoftDrop, ActionMoveLeft)
+	capsule, _ = game.GetField().GetActiveCapsule()
+	if y, x := capsule.GetCoordinate(); y != 2 || x != 2 {
+		t.Fatalf("capsule at %v,%v after soft drop and move", y, x)
+	}
+	if game.GetFrame() != uint64(gravity+1) {
+		t.Fatalf("game frame was %v", game.GetFrame())
+	}
+}
+
+func TestGameLockAndResolve(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Blue)
+	field.PutSpaceAtCoordinateIfEmpty(13, 3, virus)
+	field.PutSpaceAtCoordinateIfEmpty(14, 3, virus)
+	field.PutSpaceAtCoordinateIfEmpty(15, 3, virus)
+	field.PutSpaceAtCoordinateIfEmpty(15, 7, virus)
+
+	sequence := NewCapsuleSequence(fixedRandomizer{CapsuleColors{Blue, Red}})
+	game, err := newGame(field, GameConfig{Speed: Low}, sequence)
+	if err != nil {
+		t.Fatalf("new game failed %v", err)
+	}
+	timing := game.GetTiming()
+
+	// drop onto the viruses, the last drop locks
+	for game.GetPhase() == PhaseCapsule {
+		game.Tick(ActionSoftDrop)
+	}
+
+	if game.GetPhase() != PhaseClear {
+		t.Fatalf("lock did not start a clear, phase %v", game.GetPhase())
+	}
+	pending, ok := game.GetPendingIteration()
+	if !ok || pending.Field[12][3] != Clear {
+		t.Fatal("pending clear missing the capsule half")
+	}
+
+	// actions are ignored while resolving
+	for i := 0; i < timing.ClearFrames-1; i++ {
+		game.Tick(ActionMoveLeft)
+	}
+	if game.GetPhase() != PhaseClear || field.GetVirusCount() != 4 {
+		t.Fatal("clear happened before the pause finished")
+	}
+
+	game.Tick()
+	if field.GetVirusCount() != 1 {
+		t.Fatalf("clear left %v viruses", field.GetVirusCount())
+	}
+	if game.GetPhase() != PhaseFall {
+		t.Fatalf("red half should be falling, phase %v", game.GetPhase())
+	}
+
+	// red half falls from row 12 to the bottom one row per FallFrames
+	for i := 0; i < 3*timing.FallFrames; i++ {
+		game.Tick()
+	}
+	if game.GetPhase() != PhaseCapsule || !field.HasActiveCapsule() {
+		t.Fatalf("next capsule did not spawn, phase %v", game.GetPhase())
+	}
+
+	space, _ := field.GetSpaceAtCoordinate(15, 4)
+	if space != (Space{Pill, Unlinked, Red}) {
+		t.Fatalf("red half landed as %v", space)
+	}
+
+	if game.GetScorer().GetTotal() != 700 {
+		t.Fatalf("score was %v", game.GetScorer().GetTotal())
+	}
+	chain := game.GetLastChain()
+	if chain.ChainDepth != 1 || chain.VirusesCleared != 3 || len(chain.Steps) != 4 {
+		t.Fatalf("last chain was %v", chain)
+	}
+	if game.GetCapsuleCount() != 2 {
+		t.Fatalf("spawned %v capsules", game.GetCapsuleCount())
+	}
+}
+
+func TestGameLockDelay(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Yellow)
+	field.PutSpaceAtCoordinateIfEmpty(15, 0, virus)
+	sequence := NewCapsuleSequence(fixedRandomizer{CapsuleColors{Blue, Red}})
+	game, _ := newGame(field, GameConfig{Speed: Hi}, sequence)
+	timing := game.GetTiming()
+	timing.LockDelayFrames = 5
+	game.SetTiming(timing)
+
+	// fall to the bottom row by gravity
+	for {
+		capsule, _ := field.GetActiveCapsule()
+		if y, _ := capsule.GetCoordinate(); y == field.GetBottomRowIndex() {
+			break
+		}
+		game.Tick()
+	}
+
+	// capsule can still slide until the lock delay runs out
+	for i := 0; i < 4; i++ {
+		game.Tick(ActionMoveRight)
+	}
+	if game.GetPhase() != PhaseCapsule {
+		t.Fatal("capsule locked before lock delay")
+	}
+	game.Tick()
+	if game.GetCapsuleCount() != 2 {
+		t.Fatal("capsule did not lock after lock delay")
+	}
+
+	space, _ := field.GetSpaceAtCoordinate(field.GetBottomRowIndex(), 6)
+	if space != (Space{Pill, Right, Blue}) {
+		t.Fatalf("capsule locked as %v", space)
+	}
+}
+
+func TestGameDeterministic(t *testing.T) {
+	config := GameConfig{Level: 10, Speed: Hi, Seed: 99, Randomizer: BagRandomizer}
+	script := []Action{ActionMoveLeft, ActionRotateClockwise, ActionSoftDrop, ActionMoveRight,
+		ActionRotateCounterClockwise, ActionSoftDrop, ActionSoftDrop}
+
+	games := make([]*Game, 2)
+	for i := range games {
+		game, err := NewGame(config)
+		if err != nil {
+			t.Fatalf("new game failed %v", err)
+		}
+		games[i] = game
+
+		for frame := 0; frame < 2000; frame++ {
+			if frame%3 == 0 {
+				err = game.Tick(script[(frame/3)%len(script)])
+			} else {
+				err = game.Tick()
+			}
+			if err != nil {
+				break
+			}
+		}
+	}
+
+	for y := 0; y < games[0].GetField().GetHeight(); y++ {
+		for x := 0; x < games[0].GetField().GetWidth(); x++ {
+			first, _ := games[0].GetField().GetSpaceAtCoordinate(y, x)
+			second, _ := games[1].GetField().GetSpaceAtCoordinate(y, x)
+			if first != second {
+				t.Fatalf("games diverged at %v,%v", y, x)
+			}
+		}
+	}
+
+	if games[0].GetScorer().GetTotal() != games[1].GetScorer().GetTotal() {
+		t.Fatal("game scores diverged")
+	}
+}
+
+func TestGameWon(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Blue)
+	field.PutSpaceAtCoordinateIfEmpty(13, 3, virus)
+	field.PutSpaceAtCoordinateIfEmpty(14, 3, virus)
+	field.PutSpaceAtCoordinateIfEmpty(15, 3, virus)
+
+	sequence := NewCapsuleSequence(fixedRandomizer{CapsuleColors{Blue, Red}})
+	game, _ := newGame(field, GameConfig{Speed: Low}, sequence)
+
+	for i := 0; i < 1000 && !game.IsOver(); i++ {
+		game.Tick(ActionSoftDrop)
+	}
+
+	if game.GetState() != Won || game.GetPhase() != PhaseOver {
+		t.Fatalf("game state %v phase %v", game.GetState(), game.GetPhase())
+	}
+	if field.HasActiveCapsule() {
+		t.Fatal("capsule spawned after win")
+	}
+
+	// red half has landed before the win
+	space, _ := field.GetSpaceAtCoordinate(15, 4)
+	if space.Color != Red {
+		t.Fatal("game won before the board settled")
+	}
+
+	frame := game.GetFrame()
+	game.Tick()
+	if game.GetFrame() != frame {
+		t.Fatal("finished game advanced")
+	}
+}
+
+func TestGameLost(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Yellow)
+	field.PutSpaceAtCoordinateIfEmpty(15, 0, virus)
+
+	// stack vertical capsules in the spawn column until spawn is blocked
+	sequence := NewCapsuleSequence(fixedRandomizer{CapsuleColors{Blue, Red}})
+	game, _ := newGame(field, GameConfig{Speed: Hi}, sequence)
+
+	for i := 0; i < 10000 && !game.IsOver(); i++ {
+		if i%2 == 0 {
+			game.Tick(ActionSoftDrop, ActionRotateClockwise)
+		} else {
+			game.Tick(ActionSoftDrop)
+		}
+	}
+
+	if game.GetState() != Lost {
+		t.Fatalf("game state %v", game.GetState())
+	}
+	if !field.IsSpawnBlocked() {
+		t.Fatal("game lost without blocked spawn")
+	}
+}
+
+func TestGameStartsSettledWithLooseViruses(t *testing.T) {
+	ruleset := Ruleset{LooseViruses: true}
+	for level := 0; level <= MaxVirusLevel; level++ {
+		game, err := NewGame(GameConfig{Level: level, Speed: Med, Seed: int64(level), Ruleset: ruleset})
+		if err != nil {
+			t.Fatalf("level %v new game failed %v", level, err)
+		}
+
+		if result := game.GetField().EvaluateBoardIterationStreaks(); result.Next != NoAction {
+			logBoard(t, game.GetField())
+			t.Fatalf("level %v started with %v pending", level, result.Next)
+		}
+		if game.GetField().GetVirusCount() != GetLevelVirusCount(level) {
+			t.Fatalf("level %v started with %v viruses", level, game.GetField().GetVirusCount())
+		}
+	}
+}
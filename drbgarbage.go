@@ -0,0 +1,100 @@
+package drbreakboard
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// most garbage pieces one chain can send
+const MaxGarbagePieces = 4
+
+// a single unlinked pill sent to an opponent's board
+type GarbagePiece struct {
+	Column int
+	Color  SpaceColor
+}
+
+// get the garbage sent by a chain given the color of each cleared streak
+// fewer than two streaks sends nothing. each streak sends a piece of its
+// color, up to MaxGarbagePieces. pieces land in every other column starting
+// from a random column and wrapping around the board width
+func GenerateGarbage(colors []SpaceColor, width int, rng *rand.Rand) []GarbagePiece {
+	if len(colors) < 2 || width <= 0 {
+		return nil
+	}
+
+	count := len(colors)
+	if count > MaxGarbagePieces {
+		count = MaxGarbagePieces
+	}
+	if count > width {
+		count = width
+	}
+
+	pieces := make([]GarbagePiece, count)
+	used := make([]bool, width)
+	column := rng.Intn(width)
+
+	for i := range pieces {
+		// narrow boards can wrap onto a used column, move right to a free one
+		for used[column] {
+			column = (column + 1) % width
+		}
+		used[column] = true
+
+		pieces[i] = GarbagePiece{column, colors[i]}
+		column = (column + 2) % width
+	}
+
+	return pieces
+}
+
+// drop garbage into the top row of the board
+// garbage overwrites and unlinks a pill in its space, the normal board
+// iteration then lets it fall. pieces over a virus or the active capsule
+// are dropped. every piece is checked first, so an error delivers nothing
+func (field *PlayField) DeliverGarbage(pieces []GarbagePiece) error {
+	for _, piece := range pieces {
+		if piece.Color == Uncolored {
+			return errors.New("garbage must have a color")
+		}
+
+		if err := field.checkCoordinateInBounds(0, piece.Column); err != nil {
+			return err
+		}
+	}
+
+	for _, piece := range pieces {
+		if !field.canTakeGarbage(piece.Column) {
+			continue
+		}
+
+		err := field.ForcePutSingleSpaceIntoBoard(0, piece.Column, Space{Pill, Unlinked, piece.Color})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check if garbage can land in a top row column
+func (field *PlayField) canTakeGarbage(x int) bool {
+	if field.spaces[0][x].Content == Virus {
+		return false
+	}
+
+	if field.capsule == nil {
+		return true
+	}
+
+	linkedY, linkedX := field.capsule.GetLinkedCoordinate()
+	return !(field.capsule.y == 0 && field.capsule.x == x) && !(linkedY == 0 && linkedX == x)
+}
+
+// generate the garbage from a resolved chain and deliver it to an opponent
+// returns the garbage sent
+func SendGarbage(report ChainReport, opponent *PlayField, rng *rand.Rand) ([]GarbagePiece, error) {
+	pieces := GenerateGarbage(report.GetStreakColors(), opponent.GetWidth(), rng)
+	return pieces, opponent.DeliverGarbage(pieces)
+}
@@ -0,0 +1,146 @@
+package drbreakboard
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateGarbage(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	if GenerateGarbage([]SpaceColor{Red}, 8, rng) != nil {
+		t.Fatal("single streak sent garbage")
+	}
+
+	colors := []SpaceColor{Red, Blue, Yellow, Red, Blue}
+	pieces := GenerateGarbage(colors, 8, rng)
+	if len(pieces) != MaxGarbagePieces {
+		t.Fatalf("sent %v pieces", len(pieces))
+	}
+
+	for i, piece := range pieces {
+		if piece.Color != colors[i] {
+			t.Fatalf("piece %v was %v", i, piece.Color)
+		}
+		if i > 0 && piece.Column != (pieces[i-1].Column+2)%8 {
+			t.Fatalf("piece %v in column %v after %v", i, piece.Column, pieces[i-1].Column)
+		}
+	}
+
+	// narrow board cannot space pieces two apart
+	pieces = GenerateGarbage(colors, 3, rng)
+	if len(pieces) != 3 {
+		t.Fatalf("sent %v pieces into width 3", len(pieces))
+	}
+	used := make(map[int]bool)
+	for _, piece := range pieces {
+		if used[piece.Column] {
+			t.Fatalf("two pieces in column %v", piece.Column)
+		}
+		used[piece.Column] = true
+	}
+}
+
+func TestSendGarbage(t *testing.T) {
+	sent := make([][]GarbagePiece, 2)
+
+	for i := range sent {
+		attacker := makeTwoChainField()
+		opponent := NewPlayField(8, 16)
+
+		report, _ := attacker.ResolveUntilStable()
+		pieces, err := SendGarbage(report, opponent, rand.New(rand.NewSource(11)))
+		if err != nil {
+			t.Fatalf("send garbage failed %v", err)
+		}
+		if len(pieces) != 2 {
+			t.Fatalf("two chain sent %v pieces", len(pieces))
+		}
+		sent[i] = pieces
+
+		for _, piece := range pieces {
+			space, _ := opponent.GetSpaceAtCoordinate(0, piece.Column)
+			if space != (Space{Pill, Unlinked, piece.Color}) {
+				t.Fatalf("garbage space was %v", space)
+			}
+		}
+
+		// garbage falls to the bottom
+		opponent.ResolveUntilStable()
+		logBoard(t, opponent)
+		for _, piece := range pieces {
+			space, _ := opponent.GetSpaceAtCoordinate(opponent.GetBottomRowIndex(), piece.Column)
+			if space.Color != piece.Color {
+				t.Fatalf("garbage did not fall in column %v", piece.Column)
+			}
+		}
+	}
+
+	for i := range sent[0] {
+		if sent[0][i] != sent[1][i] {
+			t.Fatal("same seed sent different garbage")
+		}
+	}
+}
+
+func TestDeliverGarbageUnlinks(t *testing.T) {
+	field := NewPlayField(8, 16)
+	space, linkedSpace, _ := MakeLinkedPillSpaces(Right, Red, Blue)
+	field.PutTwoLinkedSpacesAtCoordinate(0, 2, space, linkedSpace)
+
+	err := field.DeliverGarbage([]GarbagePiece{{2, Yellow}})
+	if err != nil {
+		t.Fatalf("deliver failed %v", err)
+	}
+
+	partner, _ := field.GetSpaceAtCoordinate(0, 3)
+	if partner.Linkage != Unlinked {
+		t.Fatal("garbage left partner linked")
+	}
+
+	if field.DeliverGarbage([]GarbagePiece{{9, Yellow}}) == nil {
+		t.Fatal("garbage delivered out of bounds")
+	}
+	if field.DeliverGarbage([]GarbagePiece{{1, Uncolored}}) == nil {
+		t.Fatal("uncolored garbage delivered")
+	}
+}
+
+func TestDeliverGarbageAroundCapsuleAndViruses(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Red)
+	field.PutSpaceAtCoordinateIfEmpty(0, 0, virus)
+	field.SpawnCapsule(Red, Blue)
+	spawnY, spawnX := field.GetCapsuleSpawnCoordinate()
+
+	pieces := []GarbagePiece{{0, Blue}, {spawnX, Yellow}, {spawnX + 1, Yellow}, {6, Blue}}
+	if err := field.DeliverGarbage(pieces); err != nil {
+		t.Fatalf("deliver failed %v", err)
+	}
+
+	if field.GetVirusCount() != 1 {
+		t.Fatal("garbage replaced a virus")
+	}
+	for _, x := range []int{spawnX, spawnX + 1} {
+		if space, _ := field.GetSpaceAtCoordinate(spawnY, x); space.Content != Empty {
+			t.Fatalf("garbage landed under the capsule in column %v", x)
+		}
+	}
+	if space, _ := field.GetSpaceAtCoordinate(0, 6); space != (Space{Pill, Unlinked, Blue}) {
+		t.Fatalf("free column got %v", space)
+	}
+
+	// the capsule still locks where it is
+	if err := field.LockCapsule(); err != nil {
+		t.Fatalf("lock after garbage failed %v", err)
+	}
+
+	// a bad piece delivers nothing
+	field = NewPlayField(8, 16)
+	if field.DeliverGarbage([]GarbagePiece{{1, Blue}, {9, Blue}}) == nil {
+		t.Fatal("garbage delivered out of bounds")
+	}
+	if space, _ := field.GetSpaceAtCoordinate(0, 1); space.Content != Empty {
+		t.Fatal("bad delivery left a partial delivery")
+	}
+}
@@ -0,0 +1,135 @@
+package drbreakboard
+
+// number of undo states kept when a history is given no limit
+const DefaultHistoryLimit = 256
+
+// undo and redo history around a board
+// every mutation made through the history saves the board before it runs.
+// at most limit states are kept for undo, dropping the oldest first, so
+// memory is bounded by limit copies of the board
+type History struct {
+	field *PlayField
+	limit int
+	undo  []*PlayField
+	redo  []*PlayField
+}
+
+// start a history for a board, limit <= 0 uses DefaultHistoryLimit
+// mutations made to the board directly are not recorded
+func NewHistory(field *PlayField, limit int) *History {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	return &History{field: field, limit: limit}
+}
+
+func (history *History) GetField() *PlayField {
+	return history.field
+}
+
+func (history *History) CanUndo() bool {
+	return len(history.undo) > 0
+}
+
+func (history *History) CanRedo() bool {
+	return len(history.redo) > 0
+}
+
+// PutSpaceAtCoordinateIfEmpty on the board, saving it first
+func (history *History) PutSpaceAtCoordinateIfEmpty(y int, x int, space Space) error {
+	return history.record(func() error {
+		return history.field.PutSpaceAtCoordinateIfEmpty(y, x, space)
+	})
+}
+
+// PutTwoLinkedSpacesAtCoordinate on the board, saving it first
+func (history *History) PutTwoLinkedSpacesAtCoordinate(y int, x int, coordSpace Space, linkedSpace Space) error {
+	return history.record(func() error {
+		return history.field.PutTwoLinkedSpacesAtCoordinate(y, x, coordSpace, linkedSpace)
+	})
+}
+
+// ForcePutSingleSpaceIntoBoard on the board, saving it first
+func (history *History) ForcePutSingleSpaceIntoBoard(y int, x int, space Space) error {
+	return history.record(func() error {
+		return history.field.ForcePutSingleSpaceIntoBoard(y, x, space)
+	})
+}
+
+// ClearBoard on the board, saving it first
+func (history *History) ClearBoard() {
+	history.record(func() error {
+		history.field.ClearBoard()
+		return nil
+	})
+}
+
+// IterateBoard on the board, saving it first
+func (history *History) IterateBoard() error {
+	return history.record(history.field.IterateBoard)
+}
+
+// restore the board to before the last recorded mutation
+// returns false if there is nothing to undo
+func (history *History) Undo() bool {
+	if len(history.undo) == 0 {
+		return false
+	}
+
+	last := len(history.undo) - 1
+	history.redo = append(history.redo, history.field.Clone())
+	history.field.restore(history.undo[last])
+	history.undo = history.undo[:last]
+	return true
+}
+
+// reapply the last undone mutation
+// returns false if there is nothing to redo
+func (history *History) Redo() bool {
+	if len(history.redo) == 0 {
+		return false
+	}
+
+	last := len(history.redo) - 1
+	history.pushUndo(history.field.Clone())
+	history.field.restore(history.redo[last])
+	history.redo = history.redo[:last]
+	return true
+}
+
+// run a mutation, saving the board before it if it changes anything
+// a failed mutation leaves the board as it was
+func (history *History) record(mutate func() error) error {
+	snapshot := history.field.Clone()
+
+	if err := mutate(); err != nil {
+		history.field.restore(snapshot)
+		return err
+	}
+
+	if history.field.Equal(snapshot) {
+		// nothing changed, nothing to undo
+		return nil
+	}
+
+	history.pushUndo(snapshot)
+	history.redo = history.redo[:0]
+	return nil
+}
+
+// add an undo state, dropping the oldest past the limit
+func (history *History) pushUndo(snapshot *PlayField) {
+	if len(history.undo) >= history.limit {
+		copy(history.undo, history.undo[1:])
+		history.undo = history.undo[:len(history.undo)-1]
+	}
+
+	history.undo = append(history.undo, snapshot)
+}
+
+// put the board back to a clone taken from it
+// the whole board is replaced, decoding into a board can change its size
+func (field *PlayField) restore(clone *PlayField) {
+	*field = *clone.Clone()
+}
@@ -0,0 +1,153 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+func TestHistoryUndoRedoChain(t *testing.T) {
+	field := makeTwoChainField()
+	history := NewHistory(field, 0)
+
+	states := []string{FormatPlayField(field)}
+	for {
+		err := history.IterateBoard()
+		if err != nil {
+			t.Fatalf("iterate failed %v", err)
+		}
+		state := FormatPlayField(field)
+		if state == states[len(states)-1] {
+			break
+		}
+		states = append(states, state)
+	}
+
+	// clear, fall, clear
+	if len(states) != 4 {
+		t.Fatalf("chain took %v states", len(states))
+	}
+
+	// the stable iterate did not add an undo state
+	for i := len(states) - 2; i >= 0; i-- {
+		if !history.Undo() {
+			t.Fatalf("undo to state %v failed", i)
+		}
+		if FormatPlayField(field) != states[i] {
+			t.Fatalf("undo did not restore state %v", i)
+		}
+	}
+	if history.Undo() {
+		t.Fatal("undo past the first state")
+	}
+
+	for i := 1; i < len(states); i++ {
+		if !history.Redo() {
+			t.Fatalf("redo to state %v failed", i)
+		}
+		if FormatPlayField(field) != states[i] {
+			t.Fatalf("redo did not restore state %v", i)
+		}
+	}
+	if history.Redo() {
+		t.Fatal("redo past the last state")
+	}
+}
+
+func TestHistoryEdits(t *testing.T) {
+	field := NewPlayField(8, 16)
+	history := NewHistory(field, 0)
+	virus, _ := MakeVirus(Red)
+	space, linkedSpace, _ := MakeLinkedPillSpaces(Right, Blue, Yellow)
+
+	history.PutSpaceAtCoordinateIfEmpty(15, 0, virus)
+	history.PutTwoLinkedSpacesAtCoordinate(14, 0, space, linkedSpace)
+	history.ForcePutSingleSpaceIntoBoard(14, 1, Space{Pill, Unlinked, Red})
+
+	// failed edits do not add undo states
+	if history.PutSpaceAtCoordinateIfEmpty(15, 0, virus) == nil {
+		t.Fatal("put into full space worked")
+	}
+
+	history.ClearBoard()
+	if field.GetVirusCount() != 0 {
+		t.Fatal("clear did not clear")
+	}
+
+	history.Undo()
+	unlinked, _ := field.GetSpaceAtCoordinate(14, 0)
+	if unlinked.Linkage != Unlinked || field.GetVirusCount() != 1 {
+		t.Fatal("undo clear did not restore forced garbage board")
+	}
+
+	history.Undo()
+	linked, _ := field.GetSpaceAtCoordinate(14, 0)
+	if linked.Linkage != Right {
+		t.Fatal("undo force put did not relink")
+	}
+
+	// a new edit drops the redo states
+	history.PutSpaceAtCoordinateIfEmpty(0, 0, virus)
+	if history.CanRedo() {
+		t.Fatal("redo kept after new edit")
+	}
+
+	history.Undo()
+	history.Undo()
+	history.Undo()
+	if history.CanUndo() || field.GetVirusCount() != 0 {
+		t.Fatal("undo did not return to the empty board")
+	}
+}
+
+func TestHistoryLimit(t *testing.T) {
+	field := NewPlayField(8, 16)
+	history := NewHistory(field, 3)
+
+	for x := 0; x < 8; x++ {
+		history.PutSpaceAtCoordinateIfEmpty(15, x, Space{Pill, Unlinked, Blue})
+	}
+
+	undone := 0
+	for history.Undo() {
+		undone += 1
+	}
+	if undone != 3 {
+		t.Fatalf("undid %v states with a limit of 3", undone)
+	}
+
+	// the oldest states were dropped, five pills remain
+	pills := 0
+	for x := 0; x < 8; x++ {
+		space, _ := field.GetSpaceAtCoordinate(15, x)
+		if space.Content == Pill {
+			pills += 1
+		}
+	}
+	if pills != 5 {
+		t.Fatalf("%v pills left after undo", pills)
+	}
+}
+
+func TestHistoryUndoAfterDecodeResize(t *testing.T) {
+	field := NewPlayField(4, 4)
+	history := NewHistory(field, 0)
+	history.PutSpaceAtCoordinateIfEmpty(3, 0, Space{Pill, Unlinked, Red})
+
+	// decoding replaces the board with a bigger one outside the history
+	data, _ := makeTwoChainField().MarshalBinary()
+	if err := field.UnmarshalBinary(data); err != nil {
+		t.Fatalf("binary unmarshal failed %v", err)
+	}
+
+	if !history.Undo() {
+		t.Fatal("nothing to undo")
+	}
+	if field.GetWidth() != 4 || field.GetHeight() != 4 || !field.Equal(NewPlayField(4, 4)) {
+		logBoard(t, field)
+		t.Fatal("undo did not restore the small board")
+	}
+
+	if !history.Redo() || !field.Equal(makeTwoChainField()) {
+		logBoard(t, field)
+		t.Fatal("redo did not restore the decoded board")
+	}
+}
@@ -0,0 +1,192 @@
+package drbreakboard
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// pixels per space when a renderer has no cell size set
+const DefaultCellSize = 16
+
+var (
+	imageBackground = color.RGBA{0x10, 0x10, 0x18, 0xFF}
+	imageVirusEye   = color.RGBA{0x10, 0x10, 0x18, 0xFF}
+	imageRed        = color.RGBA{0xE0, 0x30, 0x30, 0xFF}
+	imageBlue       = color.RGBA{0x30, 0x70, 0xE0, 0xFF}
+	imageYellow     = color.RGBA{0xF0, 0xD0, 0x30, 0xFF}
+	imageGreen      = color.RGBA{0x30, 0xC0, 0x50, 0xFF}
+	imageUncolored  = color.RGBA{0x80, 0x80, 0x80, 0xFF}
+)
+
+// draws the board as a PNG image, CellSize pixels per space
+// the active capsule is drawn where it is
+type PNGRenderer struct {
+	CellSize int
+}
+
+// draws the board as an SVG image, CellSize units per space
+// the active capsule is drawn where it is
+type SVGRenderer struct {
+	CellSize int
+}
+
+// a sprite shape in a space, every sprite is a circle in the middle of
+// the space plus, for linked halves, a bar from the middle to the linked edge
+type spaceSprite struct {
+	centerX int
+	centerY int
+	radius  int
+	// bar covering the linked side, empty for unlinked sprites
+	bar   image.Rectangle
+	color color.RGBA
+	// viruses get eyes
+	eyes bool
+}
+
+func (renderer PNGRenderer) Render(w io.Writer, field *PlayField) error {
+	return png.Encode(w, renderer.Image(field))
+}
+
+// draw the board into an image
+func (renderer PNGRenderer) Image(field *PlayField) *image.RGBA {
+	cellSize := getCellSize(renderer.CellSize)
+	img := image.NewRGBA(image.Rect(0, 0, field.GetWidth()*cellSize, field.GetHeight()*cellSize))
+	draw.Draw(img, img.Bounds(), image.NewUniform(imageBackground), image.Point{}, draw.Src)
+
+	for _, sprite := range getSpaceSprites(field, cellSize) {
+		fillCircle(img, sprite.centerX, sprite.centerY, sprite.radius, sprite.color)
+		draw.Draw(img, sprite.bar, image.NewUniform(sprite.color), image.Point{}, draw.Src)
+		if sprite.eyes {
+			eyeRadius := getEyeRadius(sprite.radius)
+			fillCircle(img, sprite.centerX-sprite.radius/2, sprite.centerY, eyeRadius, imageVirusEye)
+			fillCircle(img, sprite.centerX+sprite.radius/2, sprite.centerY, eyeRadius, imageVirusEye)
+		}
+	}
+
+	return img
+}
+
+func (renderer SVGRenderer) Render(w io.Writer, field *PlayField) error {
+	cellSize := getCellSize(renderer.CellSize)
+	width := field.GetWidth() * cellSize
+	height := field.GetHeight() * cellSize
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%v\" height=\"%v\" viewBox=\"0 0 %v %v\">\n",
+		width, height, width, height)
+	fmt.Fprintf(&sb, "<rect width=\"%v\" height=\"%v\" fill=\"%v\"/>\n", width, height, svgColor(imageBackground))
+
+	for _, sprite := range getSpaceSprites(field, cellSize) {
+		fill := svgColor(sprite.color)
+		fmt.Fprintf(&sb, "<circle cx=\"%v\" cy=\"%v\" r=\"%v\" fill=\"%v\"/>\n",
+			sprite.centerX, sprite.centerY, sprite.radius, fill)
+		if !sprite.bar.Empty() {
+			fmt.Fprintf(&sb, "<rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\" fill=\"%v\"/>\n",
+				sprite.bar.Min.X, sprite.bar.Min.Y, sprite.bar.Dx(), sprite.bar.Dy(), fill)
+		}
+		if sprite.eyes {
+			eyeRadius := getEyeRadius(sprite.radius)
+			eyeFill := svgColor(imageVirusEye)
+			fmt.Fprintf(&sb, "<circle cx=\"%v\" cy=\"%v\" r=\"%v\" fill=\"%v\"/>\n",
+				sprite.centerX-sprite.radius/2, sprite.centerY, eyeRadius, eyeFill)
+			fmt.Fprintf(&sb, "<circle cx=\"%v\" cy=\"%v\" r=\"%v\" fill=\"%v\"/>\n",
+				sprite.centerX+sprite.radius/2, sprite.centerY, eyeRadius, eyeFill)
+		}
+	}
+
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// get the sprite for every non empty space on the board
+func getSpaceSprites(field *PlayField, cellSize int) []spaceSprite {
+	sprites := make([]spaceSprite, 0)
+	radius := cellSize/2 - cellSize/8
+
+	for y := 0; y < field.GetHeight(); y++ {
+		for x := 0; x < field.GetWidth(); x++ {
+			space, _ := field.GetDrawnSpaceAtCoordinate(y, x)
+			if space.Content == Empty {
+				continue
+			}
+
+			sprite := spaceSprite{
+				centerX: x*cellSize + cellSize/2,
+				centerY: y*cellSize + cellSize/2,
+				radius:  radius,
+				color:   getImageColor(space.Color),
+				eyes:    space.Content == Virus,
+			}
+
+			// linked halves reach the edge they share with their partner
+			cell := image.Rect(x*cellSize, y*cellSize, (x+1)*cellSize, (y+1)*cellSize)
+			switch space.Linkage {
+			case Up:
+				sprite.bar = image.Rect(sprite.centerX-radius, cell.Min.Y, sprite.centerX+radius, sprite.centerY)
+			case Down:
+				sprite.bar = image.Rect(sprite.centerX-radius, sprite.centerY, sprite.centerX+radius, cell.Max.Y)
+			case Left:
+				sprite.bar = image.Rect(cell.Min.X, sprite.centerY-radius, sprite.centerX, sprite.centerY+radius)
+			case Right:
+				sprite.bar = image.Rect(sprite.centerX, sprite.centerY-radius, cell.Max.X, sprite.centerY+radius)
+			}
+
+			sprites = append(sprites, sprite)
+		}
+	}
+
+	return sprites
+}
+
+func getCellSize(cellSize int) int {
+	if cellSize <= 0 {
+		return DefaultCellSize
+	}
+	return cellSize
+}
+
+func getEyeRadius(radius int) int {
+	if radius < 4 {
+		return 1
+	}
+	return radius / 4
+}
+
+func getImageColor(spaceColor SpaceColor) color.RGBA {
+	switch spaceColor {
+	case Red:
+		return imageRed
+	case Blue:
+		return imageBlue
+	case Yellow:
+		return imageYellow
+	case Green:
+		return imageGreen
+	}
+	return imageUncolored
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// fill every pixel whose center is inside a circle
+func fillCircle(img *image.RGBA, centerX int, centerY int, radius int, c color.RGBA) {
+	for y := centerY - radius; y <= centerY+radius; y++ {
+		for x := centerX - radius; x <= centerX+radius; x++ {
+			// compare in doubled coordinates to measure from pixel centers
+			dx := 2*(x-centerX) + 1
+			dy := 2*(y-centerY) + 1
+			if dx*dx+dy*dy <= 4*radius*radius {
+				img.SetRGBA(x, y, c)
+			}
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package drbreakboard
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestPNGRender(t *testing.T) {
+	field := makeTwoChainField()
+	field.SpawnCapsule(Yellow, Blue)
+
+	var buffer bytes.Buffer
+	err := PNGRenderer{CellSize: 10}.Render(&buffer, field)
+	if err != nil {
+		t.Fatalf("png render failed %v", err)
+	}
+
+	img, err := png.Decode(&buffer)
+	if err != nil {
+		t.Fatalf("png did not decode %v", err)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() != 80 || bounds.Dy() != 160 {
+		t.Fatalf("png was %vx%v", bounds.Dx(), bounds.Dy())
+	}
+
+	checks := []struct {
+		x, y     int
+		expected SpaceColor
+	}{
+		// red virus body, left of its eyes
+		{1, 145, Red},
+		// horizontal pill joins across the space edge
+		{10, 115, Blue},
+		{9, 115, Red},
+		// active capsule
+		{35, 5, Yellow},
+		{45, 5, Blue},
+	}
+	for _, check := range checks {
+		r, g, b, _ := img.At(check.x, check.y).RGBA()
+		want := getImageColor(check.expected)
+		if uint8(r>>8) != want.R || uint8(g>>8) != want.G || uint8(b>>8) != want.B {
+			t.Fatalf("pixel %v,%v was %v,%v,%v", check.x, check.y, r>>8, g>>8, b>>8)
+		}
+	}
+
+	// empty space is background
+	r, g, b, _ := img.At(75, 75).RGBA()
+	if uint8(r>>8) != imageBackground.R || uint8(g>>8) != imageBackground.G || uint8(b>>8) != imageBackground.B {
+		t.Fatal("empty space was drawn on")
+	}
+}
+
+func TestSVGRender(t *testing.T) {
+	field := makeTwoChainField()
+
+	var buffer bytes.Buffer
+	err := SVGRenderer{}.Render(&buffer, field)
+	if err != nil {
+		t.Fatalf("svg render failed %v", err)
+	}
+	output := buffer.String()
+
+	if !strings.HasPrefix(output, "<svg") || !strings.HasSuffix(output, "</svg>\n") {
+		t.Fatal("svg not wrapped in svg element")
+	}
+
+	// one body per space plus two eyes per virus
+	viruses := field.GetVirusCount()
+	if strings.Count(output, "<circle") != viruses*3+2 {
+		t.Fatalf("svg had %v circles", strings.Count(output, "<circle"))
+	}
+
+	// background plus the bar of each linked half
+	if strings.Count(output, "<rect") != 3 {
+		t.Fatalf("svg had %v rects", strings.Count(output, "<rect"))
+	}
+
+	if !strings.Contains(output, svgColor(imageYellow)) {
+		t.Fatal("yellow virus missing")
+	}
+}
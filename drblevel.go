@@ -0,0 +1,171 @@
+package drbreakboard
+
+import (
+	"errors"
+	"math/rand"
+)
+
+// highest level with its own virus count, later levels use the same count
+const MaxVirusLevel = 20
+
+// get the number of viruses on a level, 4 per level starting at 4
+func GetLevelVirusCount(level int) int {
+	if level < 0 {
+		level = 0
+	}
+
+	if level > MaxVirusLevel {
+		level = MaxVirusLevel
+	}
+
+	return (level + 1) * 4
+}
+
+// get how many rows up from the bottom viruses can be placed on a level
+func GetLevelMaxVirusHeight(level int) int {
+	switch {
+	case level >= 19:
+		return 13
+	case level >= 17:
+		return 12
+	case level >= 15:
+		return 11
+	default:
+		return 10
+	}
+}
+
+// clear the board and fill the bottom with viruses for a level
+// viruses are placed at a random space in the level's allowed rows, using
+// the ruleset's colors, and do not line up one short of a match in a row or
+// column. a rejected color cycles to the next color, and a space with no
+// legal color moves the virus one space right, wrapping into the next row down.
+// if no space is legal the virus goes in the first that does not make a
+// match, short diagonal matches with few colors can still run out of spaces
+// on the highest levels.
+// when viruses are not fixed each one drops onto the space below it first,
+// so the board starts settled. the same level and rng seed always produce
+// the same board
+func (field *PlayField) GenerateVirusLevel(level int, rng *rand.Rand) error {
+	if level < 0 {
+		return errors.New("level cannot be negative")
+	}
+
+	field.ClearBoard()
+
+	width := field.GetWidth()
+	height := GetLevelMaxVirusHeight(level)
+	if height > field.GetHeight() {
+		height = field.GetHeight()
+	}
+	topRow := field.GetHeight() - height
+
+	remaining := GetLevelVirusCount(level)
+	if remaining > width*height {
+		return errors.New("level has more viruses than the board can hold")
+	}
+
+	ruleset := field.GetRuleset()
+	colors := ruleset.GetColors()
+	for remaining > 0 {
+		y := topRow + rng.Intn(height)
+		x := rng.Intn(width)
+
+		// colors go in order with one virus in every cycle picked at random
+		colorIndex := remaining % (len(colors) + 1)
+		if colorIndex == len(colors) {
+			colorIndex = rng.Intn(len(colors))
+		}
+
+		// once no space is left that keeps viruses from lining up one short
+		// of a match, settle for one that does not make a match
+		placed := false
+		for _, longest := range []int{ruleset.MinMatchLength - 2, ruleset.MinMatchLength - 1} {
+			spaceY, spaceX := y, x
+			for tries := 0; tries < width*height && !placed; tries++ {
+				placed = field.putVirusIfLegal(spaceY, spaceX, colors, colorIndex, longest)
+				if placed {
+					break
+				}
+
+				// move to the next space, wrapping right to left and bottom to top
+				spaceX += 1
+				if spaceX >= width {
+					spaceX = 0
+					spaceY += 1
+					if spaceY > field.GetBottomRowIndex() {
+						spaceY = topRow
+					}
+				}
+			}
+		}
+
+		if !placed {
+			return errors.New("no legal space left for virus")
+		}
+
+		remaining -= 1
+	}
+
+	return nil
+}
+
+// put a virus in an empty space, starting at the color index and cycling
+// through colors until one does not line up more than longest in a row
+// viruses that are not fixed land on whatever is below them
+// returns false if no color fit
+func (field *PlayField) putVirusIfLegal(y int, x int, colors []SpaceColor, colorIndex int, longest int) bool {
+	if field.checkCoordinateInBoundsAndEmpty(y, x) != nil {
+		return false
+	}
+
+	if field.GetRuleset().LooseViruses {
+		for field.checkCoordinateInBoundsAndEmpty(y+1, x) == nil {
+			y += 1
+		}
+	}
+
+	for i := range colors {
+		color := colors[(colorIndex+i)%len(colors)]
+		if field.makesLine(y, x, color, longest+1) {
+			continue
+		}
+
+		virus, _ := MakeVirus(color)
+		field.putSpaceAtCoordinate(y, x, virus)
+		return true
+	}
+
+	return false
+}
+
+// check if a color at a coordinate would line up length or more of the
+// same color in its row or column, or its diagonals with diagonal rules
+func (field *PlayField) makesLine(y int, x int, color SpaceColor, length int) bool {
+	horizontal := 1 + field.countColorRun(y, x, 0, -1, color) + field.countColorRun(y, x, 0, 1, color)
+	vertical := 1 + field.countColorRun(y, x, -1, 0, color) + field.countColorRun(y, x, 1, 0, color)
+	if horizontal >= length || vertical >= length {
+		return true
+	}
+
+	if !field.GetRuleset().Diagonals {
+		return false
+	}
+
+	down := 1 + field.countColorRun(y, x, -1, -1, color) + field.countColorRun(y, x, 1, 1, color)
+	up := 1 + field.countColorRun(y, x, 1, -1, color) + field.countColorRun(y, x, -1, 1, color)
+	return down >= length || up >= length
+}
+
+// count the spaces of a color in a line starting next to a coordinate
+func (field *PlayField) countColorRun(y int, x int, dy int, dx int, color SpaceColor) int {
+	count := 0
+	for {
+		y += dy
+		x += dx
+		if field.checkCoordinateInBounds(y, x) != nil || field.spaces[y][x].Color != color {
+			return count
+		}
+		count += 1
+	}
+}
@@ -0,0 +1,124 @@
+package drbreakboard
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenerateVirusLevel(t *testing.T) {
+	for level := 0; level <= MaxVirusLevel+2; level++ {
+		field := NewPlayField(8, 16)
+		err := field.GenerateVirusLevel(level, rand.New(rand.NewSource(int64(level))))
+		if err != nil {
+			t.Fatalf("level %v failed to generate, %v", level, err)
+		}
+
+		if field.GetVirusCount() != GetLevelVirusCount(level) {
+			t.Fatalf("level %v has %v viruses", level, field.GetVirusCount())
+		}
+
+		topRow := field.GetHeight() - GetLevelMaxVirusHeight(level)
+		for y := 0; y < field.GetHeight(); y++ {
+			for x := 0; x < field.GetWidth(); x++ {
+				space, _ := field.GetSpaceAtCoordinate(y, x)
+				if space.Content == Empty {
+					continue
+				}
+				if y < topRow {
+					t.Fatalf("level %v has virus above max height at %v,%v", level, y, x)
+				}
+				if field.countColorRun(y, x, 0, 1, space.Color) >= 2 ||
+					field.countColorRun(y, x, 1, 0, space.Color) >= 2 {
+					t.Fatalf("level %v has three in a row at %v,%v", level, y, x)
+				}
+			}
+		}
+
+		_, nextIter, _ := field.EvaluateBoardIteration()
+		if nextIter != NoAction {
+			t.Fatalf("level %v is not stable", level)
+		}
+	}
+
+	field := NewPlayField(8, 16)
+	if field.GenerateVirusLevel(-1, rand.New(rand.NewSource(1))) == nil {
+		t.Fatal("negative level generated")
+	}
+
+	// too small for level
+	field = NewPlayField(1, 2)
+	if field.GenerateVirusLevel(0, rand.New(rand.NewSource(1))) == nil {
+		t.Fatal("level generated into a board too small for it")
+	}
+}
+
+func TestGenerateVirusLevelDeterministic(t *testing.T) {
+	first := NewPlayField(8, 16)
+	second := NewPlayField(8, 16)
+	first.GenerateVirusLevel(15, rand.New(rand.NewSource(42)))
+	second.GenerateVirusLevel(15, rand.New(rand.NewSource(42)))
+	logBoard(t, first)
+
+	for y := 0; y < first.GetHeight(); y++ {
+		for x := 0; x < first.GetWidth(); x++ {
+			firstSpace, _ := first.GetSpaceAtCoordinate(y, x)
+			secondSpace, _ := second.GetSpaceAtCoordinate(y, x)
+			if firstSpace != secondSpace {
+				t.Fatalf("same seed made different boards at %v,%v", y, x)
+			}
+		}
+	}
+}
+
+func TestGenerateVirusLevelRulesets(t *testing.T) {
+	rulesets := []Ruleset{
+		{5, 3, false, false},
+		{4, 4, false, false},
+		{4, 3, true, false},
+		{3, 4, false, false},
+		{4, 3, false, true},
+		{4, 4, true, true},
+	}
+
+	for _, ruleset := range rulesets {
+		for level := 0; level <= MaxVirusLevel; level += 5 {
+			field, _ := NewPlayFieldWithRuleset(8, 16, ruleset)
+			err := field.GenerateVirusLevel(level, rand.New(rand.NewSource(int64(level))))
+			if err != nil {
+				t.Fatalf("ruleset %+v level %v failed to generate, %v", ruleset, level, err)
+			}
+
+			if field.EvaluateBoardIterationStreaks().Next != NoAction {
+				logBoard(t, field)
+				t.Fatalf("ruleset %+v level %v is not stable", ruleset, level)
+			}
+		}
+	}
+}
+
+func TestGenerateVirusLevelEveryRuleset(t *testing.T) {
+	// every ruleset without diagonals fills every level
+	for length := MinRulesetMatchLength; length <= 6; length++ {
+		for colors := MinColorCount; colors <= MaxColorCount; colors++ {
+			for _, loose := range []bool{false, true} {
+				ruleset := Ruleset{length, colors, false, loose}
+				for level := 0; level <= MaxVirusLevel; level++ {
+					field, _ := NewPlayFieldWithRuleset(8, 16, ruleset)
+					err := field.GenerateVirusLevel(level, rand.New(rand.NewSource(int64(level))))
+					if err != nil {
+						t.Fatalf("ruleset %+v level %v failed to generate, %v", ruleset, level, err)
+					}
+
+					if field.EvaluateBoardIterationStreaks().Next != NoAction {
+						logBoard(t, field)
+						t.Fatalf("ruleset %+v level %v is not stable", ruleset, level)
+					}
+				}
+			}
+		}
+	}
+
+	if _, err := NewGame(GameConfig{Level: 20, Ruleset: Ruleset{4, 1, false, false}}); err == nil {
+		t.Fatal("one color game started")
+	}
+}
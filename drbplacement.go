@@ -0,0 +1,130 @@
+package drbreakboard
+
+// actions tried from each capsule position when searching for placements
+var placementActions = []Action{
+	ActionMoveLeft,
+	ActionMoveRight,
+	ActionRotateClockwise,
+	ActionRotateCounterClockwise,
+	ActionSoftDrop,
+}
+
+// a spot the capsule can reach and lock in, with the board it leaves
+type Placement struct {
+	// the capsule as it locks
+	Capsule ActiveCapsule
+	// actions from the spawned capsule to the spot, ignoring gravity,
+	// ending with the soft drop that locks it
+	Path []Action
+	// the board after locking the capsule and resolving every chain
+	Field *PlayField
+	// the chain the lock set off
+	Chain ChainReport
+}
+
+// a capsule position found by the placement search and how it was reached
+type placementNode struct {
+	capsule ActiveCapsule
+	parent  int
+	action  Action
+}
+
+// find every spot a capsule with these colors can lock in
+// the search starts from a capsule at the spawn point and follows moves,
+// rotations and soft drops, so spots walled off from the spawn are left
+// out. spots that leave the same board are only listed once, by the
+// shortest path. any active capsule on the board is ignored.
+// errors if the spawn spaces are blocked
+func (field *PlayField) EnumeratePlacements(colors CapsuleColors) ([]Placement, error) {
+	search := field.Clone()
+	search.capsule = nil
+	if err := search.SpawnCapsule(colors.Left, colors.Right); err != nil {
+		return nil, err
+	}
+
+	nodes := []placementNode{{*search.capsule, -1, ActionNone}}
+	visited := map[ActiveCapsule]bool{*search.capsule: true}
+	placements := make([]Placement, 0)
+
+	// boards already left by a placement, by hash
+	boards := make(map[uint64][]*PlayField)
+	evaluator := NewEvaluator()
+
+	// breadth first so every position is reached by a shortest path
+	for i := 0; i < len(nodes); i++ {
+		current := nodes[i].capsule
+		search.capsule = &current
+
+		if search.IsCapsuleGrounded() {
+			placement, err := field.makePlacement(nodes, i, evaluator)
+			if err != nil {
+				return nil, err
+			}
+
+			hash := placement.Field.Hash()
+			if !containsEqualField(boards[hash], placement.Field) {
+				boards[hash] = append(boards[hash], placement.Field)
+				placements = append(placements, placement)
+			}
+		}
+
+		for _, action := range placementActions {
+			next := current
+			search.capsule = &next
+
+			moved, err := search.applyCapsuleAction(action)
+			if err != nil {
+				return nil, err
+			}
+			if !moved || visited[*search.capsule] {
+				continue
+			}
+
+			visited[*search.capsule] = true
+			nodes = append(nodes, placementNode{*search.capsule, i, action})
+		}
+	}
+
+	return placements, nil
+}
+
+// lock the capsule of a searched node into a copy of the board and resolve it
+func (field *PlayField) makePlacement(nodes []placementNode, index int, evaluator *Evaluator) (Placement, error) {
+	capsule := nodes[index].capsule
+	placement := Placement{Capsule: capsule, Path: []Action{ActionSoftDrop}}
+
+	// walk back to the spawn, building the path from the end
+	for i := index; nodes[i].parent >= 0; i = nodes[i].parent {
+		placement.Path = append([]Action{nodes[i].action}, placement.Path...)
+	}
+
+	placement.Field = field.Clone()
+	placement.Field.capsule = nil
+
+	coordSpace, linkedSpace, err := MakeLinkedPillSpaces(capsule.linkage, capsule.coordColor, capsule.linkedColor)
+	if err != nil {
+		return Placement{}, err
+	}
+
+	err = placement.Field.PutTwoLinkedSpacesAtCoordinate(capsule.y, capsule.x, coordSpace, linkedSpace)
+	if err != nil {
+		return Placement{}, err
+	}
+
+	placement.Chain, err = placement.Field.resolveUntilStable(evaluator)
+	if err != nil {
+		return Placement{}, err
+	}
+
+	return placement, nil
+}
+
+// check if a board equal to field is in a list
+func containsEqualField(fields []*PlayField, field *PlayField) bool {
+	for _, other := range fields {
+		if other.Equal(field) {
+			return true
+		}
+	}
+	return false
+}
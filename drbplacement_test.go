@@ -0,0 +1,195 @@
+package drbreakboard
+
+import (
+	"errors"
+	"testing"
+)
+
+// follow a placement path from the spawn and check it locks where promised
+func checkPlacementPath(t *testing.T, field *PlayField, colors CapsuleColors, placement Placement) {
+	t.Helper()
+	replay := field.Clone()
+	replay.SpawnCapsule(colors.Left, colors.Right)
+
+	for i, action := range placement.Path {
+		moved, err := replay.applyCapsuleAction(action)
+		if err != nil {
+			t.Fatalf("path action %v failed %v", action, err)
+		}
+		last := i == len(placement.Path)-1
+		if moved == last {
+			t.Fatalf("path action %v of %v moved %v", i, placement.Path, moved)
+		}
+	}
+
+	capsule, _ := replay.GetActiveCapsule()
+	if capsule != placement.Capsule {
+		t.Fatalf("path %v ended at %v, not %v", placement.Path, capsule, placement.Capsule)
+	}
+}
+
+func TestEnumeratePlacementsEmptyBoard(t *testing.T) {
+	field := NewPlayField(8, 16)
+
+	tests := []struct {
+		colors CapsuleColors
+		count  int
+	}{
+		// 7 horizontal and 8 vertical spots, each both ways round
+		{CapsuleColors{Red, Blue}, 30},
+		// turning a single colored capsule around changes nothing
+		{CapsuleColors{Red, Red}, 15},
+	}
+
+	for _, test := range tests {
+		placements, err := field.EnumeratePlacements(test.colors)
+		if err != nil {
+			t.Fatalf("enumerate failed %v", err)
+		}
+		if len(placements) != test.count {
+			t.Fatalf("colors %v had %v placements", test.colors, len(placements))
+		}
+
+		for _, placement := range placements {
+			checkPlacementPath(t, field, test.colors, placement)
+
+			y, x := placement.Capsule.GetCoordinate()
+			if y != field.GetBottomRowIndex() {
+				t.Fatalf("placement at %v,%v is not on the bottom", y, x)
+			}
+			space, _ := placement.Field.GetSpaceAtCoordinate(y, x)
+			if space.Content != Pill || placement.Field.HasActiveCapsule() {
+				t.Fatal("placement board does not have the capsule locked in")
+			}
+		}
+	}
+
+	if field.HasActiveCapsule() {
+		t.Fatal("enumerating changed the board")
+	}
+}
+
+func TestEnumeratePlacementsWalls(t *testing.T) {
+	// a wall of viruses with a gap only at the left edge, the spaces under
+	// the shelf on the right can only be reached by sliding under it
+	field, _ := ParsePlayField(`
+		XXX XXX XXX XXX XXX XXX
+		XXX XXX XXX XXX XXX XXX
+		XXX XXX XXX XXX XXX XXX
+		XXX VRX VBX VYX VRX VBX
+		XXX XXX XXX XXX XXX XXX
+		VYX VBX VRX VYX VBX VRX
+		VBX VYX VBX VRX XXX XXX
+		VRX VRX VYX VBX XXX XXX`)
+	sealed := field.Clone()
+	sealed.ForcePutSingleSpaceIntoBoard(3, 0, Space{Virus, Unlinked, Yellow})
+
+	colors := CapsuleColors{Yellow, Blue}
+	placements, err := field.EnumeratePlacements(colors)
+	if err != nil {
+		t.Fatalf("enumerate failed %v", err)
+	}
+
+	under := 0
+	for _, placement := range placements {
+		checkPlacementPath(t, field, colors, placement)
+		if y, _ := placement.Capsule.GetCoordinate(); y == 4 {
+			under += 1
+		}
+		// the pocket at the bottom right is closed off
+		if y, _ := placement.Capsule.GetCoordinate(); y > 5 {
+			t.Fatalf("reached the closed pocket with %v", placement.Path)
+		}
+	}
+	if under == 0 {
+		t.Fatal("no placements under the shelf")
+	}
+
+	// with the gap closed nothing gets under the shelf
+	placements, _ = sealed.EnumeratePlacements(colors)
+	for _, placement := range placements {
+		if y, _ := placement.Capsule.GetCoordinate(); y > 2 {
+			t.Fatalf("got through a closed wall with %v", placement.Path)
+		}
+	}
+}
+
+func TestEnumeratePlacementsResolves(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Red)
+	for y := 13; y <= 15; y++ {
+		field.PutSpaceAtCoordinateIfEmpty(y, 0, virus)
+	}
+
+	placements, err := field.EnumeratePlacements(CapsuleColors{Red, Blue})
+	if err != nil {
+		t.Fatalf("enumerate failed %v", err)
+	}
+
+	clearing := 0
+	for _, placement := range placements {
+		if placement.Chain.VirusesCleared == 0 {
+			continue
+		}
+		clearing += 1
+
+		// the red half lands on top of the viruses, the blue half falls after
+		if placement.Field.GetVirusCount() != 0 || placement.Chain.ChainDepth != 1 {
+			t.Fatalf("clearing placement left %v viruses", placement.Field.GetVirusCount())
+		}
+		_, next, _ := placement.Field.EvaluateBoardIteration()
+		if next != NoAction {
+			t.Fatal("placement board not resolved")
+		}
+	}
+
+	// red bottom half standing on the viruses, or red left half lying on them
+	if clearing != 2 {
+		t.Fatalf("%v placements cleared viruses", clearing)
+	}
+
+	full := NewPlayField(8, 16)
+	full.PutSpaceAtCoordinateIfEmpty(0, 3, virus)
+	if _, err = full.EnumeratePlacements(CapsuleColors{Red, Blue}); !errors.Is(err, ErrSpawnBlocked) {
+		t.Fatalf("blocked spawn gave %v", err)
+	}
+}
+
+func TestEnumeratePlacementsSameBoardOnce(t *testing.T) {
+	// with three in a row clearing, a red red capsule standing on the red
+	// virus or lying either side of it clears the board, three capsule
+	// spots that leave the same empty board
+	field, _ := NewPlayFieldWithRuleset(8, 16, Ruleset{3, 3, false, false})
+	virus, _ := MakeVirus(Red)
+	field.PutSpaceAtCoordinateIfEmpty(15, 3, virus)
+	colors := CapsuleColors{Red, Red}
+
+	placements, err := field.EnumeratePlacements(colors)
+	if err != nil {
+		t.Fatalf("enumerate failed %v", err)
+	}
+
+	clearing := make([]Placement, 0)
+	for i, placement := range placements {
+		checkPlacementPath(t, field, colors, placement)
+		if placement.Chain.VirusesCleared > 0 {
+			clearing = append(clearing, placement)
+		}
+
+		for _, other := range placements[:i] {
+			if other.Field.Equal(placement.Field) {
+				t.Fatalf("paths %v and %v leave the same board", other.Path, placement.Path)
+			}
+		}
+	}
+
+	if len(clearing) != 1 {
+		t.Fatalf("%v placements cleared the board", len(clearing))
+	}
+
+	// standing on the virus is a rotation and drops from the spawn, lying
+	// beside it also needs moves, so the shortest path stands on it
+	if y, x := clearing[0].Capsule.GetCoordinate(); clearing[0].Capsule.GetLinkage() != Up || y != 14 || x != 3 {
+		t.Fatalf("kept %v by path %v", clearing[0].Capsule, clearing[0].Path)
+	}
+}
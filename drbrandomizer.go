@@ -0,0 +1,163 @@
+package drbreakboard
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+)
+
+type RandomizerKind int
+
+const (
+	LFSRRandomizer RandomizerKind = iota
+	BagRandomizer
+)
+
+// seed used by the LFSR randomizer when given a zero seed,
+// a zero LFSR state never changes
+const defaultLFSRSeed = 0x8988
+
+// the colors of a new capsule as it spawns
+type CapsuleColors struct {
+	Left  SpaceColor
+	Right SpaceColor
+}
+
+// source of capsule colors
+type CapsuleRandomizer interface {
+	NextCapsule() CapsuleColors
+}
+
+// every left and right color combination of the classic colors
+var capsuleColorPairs = getCapsuleColorPairs(ClassicRuleset.GetColors())
+
+// make a randomizer of the given kind from a seed
+// the LFSR randomizer folds the whole seed into its 16 bit state
+func NewCapsuleRandomizer(kind RandomizerKind, seed int64) (CapsuleRandomizer, error) {
+	return NewCapsuleRandomizerWithRuleset(kind, seed, ClassicRuleset)
+}
+
+// make a randomizer of the given kind from a seed, dealing capsules
+// in the ruleset's colors
+func NewCapsuleRandomizerWithRuleset(kind RandomizerKind, seed int64, ruleset Ruleset) (CapsuleRandomizer, error) {
+	if err := ruleset.check(); err != nil {
+		return nil, err
+	}
+
+	pairs := getCapsuleColorPairs(ruleset.GetColors())
+	switch kind {
+	case LFSRRandomizer:
+		randomizer := NewLFSRCapsuleRandomizer(foldLFSRSeed(seed))
+		randomizer.pairs = pairs
+		return randomizer, nil
+	case BagRandomizer:
+		randomizer := NewBagCapsuleRandomizer(seed)
+		randomizer.pairs = pairs
+		return randomizer, nil
+	default:
+		return nil, errors.New("unknown randomizer kind")
+	}
+}
+
+// fold a 64 bit seed into 16 bits by xoring its 16 bit chunks, so seeds
+// that differ anywhere deal different capsules. seeds below 65536 are kept
+// as is, a fold of zero uses the default seed like a zero seed does
+func foldLFSRSeed(seed int64) uint16 {
+	folded := uint16(0)
+	for bits := uint64(seed); bits != 0; bits >>= 16 {
+		folded ^= uint16(bits)
+	}
+	return folded
+}
+
+// get every left and right combination of colors, left color first
+func getCapsuleColorPairs(colors []SpaceColor) []CapsuleColors {
+	pairs := make([]CapsuleColors, 0, len(colors)*len(colors))
+	for _, left := range colors {
+		for _, right := range colors {
+			pairs = append(pairs, CapsuleColors{left, right})
+		}
+	}
+	return pairs
+}
+
+// classic randomizer built on a 16 bit linear feedback shift register
+// each capsule steps the register and picks a color pair from its value
+type LFSRCapsuleRandomizer struct {
+	state uint16
+	pairs []CapsuleColors
+}
+
+func NewLFSRCapsuleRandomizer(seed uint16) *LFSRCapsuleRandomizer {
+	if seed == 0 {
+		seed = defaultLFSRSeed
+	}
+
+	return &LFSRCapsuleRandomizer{seed, capsuleColorPairs}
+}
+
+func (randomizer *LFSRCapsuleRandomizer) NextCapsule() CapsuleColors {
+	randomizer.step()
+	return randomizer.pairs[int(randomizer.state)%len(randomizer.pairs)]
+}
+
+// shift the register right, feeding bit 1 of each byte back in at the top
+func (randomizer *LFSRCapsuleRandomizer) step() {
+	feedback := ((randomizer.state >> 9) ^ (randomizer.state >> 1)) & 1
+	randomizer.state = randomizer.state>>1 | feedback<<15
+}
+
+// randomizer that deals every color pair once in a shuffled order
+// before reshuffling, so no pair is ever absent for long
+type BagCapsuleRandomizer struct {
+	rng   *rand.Rand
+	bag   []CapsuleColors
+	pairs []CapsuleColors
+}
+
+func NewBagCapsuleRandomizer(seed int64) *BagCapsuleRandomizer {
+	return &BagCapsuleRandomizer{rand.New(rand.NewSource(seed)), nil, capsuleColorPairs}
+}
+
+func (randomizer *BagCapsuleRandomizer) NextCapsule() CapsuleColors {
+	if len(randomizer.bag) == 0 {
+		// refill and shuffle the bag
+		randomizer.bag = append(randomizer.bag, randomizer.pairs...)
+		randomizer.rng.Shuffle(len(randomizer.bag), func(i int, j int) {
+			randomizer.bag[i], randomizer.bag[j] = randomizer.bag[j], randomizer.bag[i]
+		})
+	}
+
+	capsule := randomizer.bag[0]
+	randomizer.bag = randomizer.bag[1:]
+	return capsule
+}
+
+// capsule colors drawn from a randomizer and kept so several players
+// can read the same sequence, each keeping its own index
+type CapsuleSequence struct {
+	lock       sync.Mutex
+	randomizer CapsuleRandomizer
+	capsules   []CapsuleColors
+}
+
+func NewCapsuleSequence(randomizer CapsuleRandomizer) *CapsuleSequence {
+	return &CapsuleSequence{randomizer: randomizer}
+}
+
+// get the capsule at an index in the sequence, drawing more from the
+// randomizer as needed
+func (sequence *CapsuleSequence) GetCapsule(index int) (CapsuleColors, error) {
+	if index < 0 {
+		return CapsuleColors{}, errors.New("capsule index cannot be negative")
+	}
+
+	sequence.lock.Lock()
+	defer sequence.lock.Unlock()
+
+	for len(sequence.capsules) <= index {
+		sequence.capsules = append(sequence.capsules, sequence.randomizer.NextCapsule())
+	}
+
+	return sequence.capsules[index], nil
+}
@@ -0,0 +1,110 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+func TestRandomizerDeterministic(t *testing.T) {
+	for _, kind := range []RandomizerKind{LFSRRandomizer, BagRandomizer} {
+		first, err := NewCapsuleRandomizer(kind, 1234)
+		if err != nil {
+			t.Fatalf("randomizer kind %v failed %v", kind, err)
+		}
+		second, _ := NewCapsuleRandomizer(kind, 1234)
+
+		seen := make(map[CapsuleColors]bool)
+		for i := 0; i < 500; i++ {
+			firstCapsule := first.NextCapsule()
+			if firstCapsule != second.NextCapsule() {
+				t.Fatalf("randomizer kind %v diverged at capsule %v", kind, i)
+			}
+			if firstCapsule.Left == Uncolored || firstCapsule.Right == Uncolored {
+				t.Fatalf("randomizer kind %v made uncolored capsule", kind)
+			}
+			seen[firstCapsule] = true
+		}
+
+		if len(seen) != len(capsuleColorPairs) {
+			t.Fatalf("randomizer kind %v only made %v color pairs", kind, len(seen))
+		}
+	}
+
+	_, err := NewCapsuleRandomizer(RandomizerKind(99), 1)
+	if err == nil {
+		t.Fatal("unknown randomizer kind was made")
+	}
+}
+
+func TestBagRandomizerDealsEveryPair(t *testing.T) {
+	randomizer := NewBagCapsuleRandomizer(7)
+
+	for bag := 0; bag < 3; bag++ {
+		seen := make(map[CapsuleColors]bool)
+		for i := 0; i < len(capsuleColorPairs); i++ {
+			seen[randomizer.NextCapsule()] = true
+		}
+		if len(seen) != len(capsuleColorPairs) {
+			t.Fatalf("bag %v repeated a pair", bag)
+		}
+	}
+}
+
+func TestCapsuleSequenceShared(t *testing.T) {
+	sequence := NewCapsuleSequence(NewLFSRCapsuleRandomizer(0))
+	reference := NewLFSRCapsuleRandomizer(0)
+
+	// one player reads ahead, the other catches up
+	ahead, _ := sequence.GetCapsule(20)
+	for i := 0; i <= 20; i++ {
+		capsule, err := sequence.GetCapsule(i)
+		if err != nil {
+			t.Fatalf("get capsule %v errored %v", i, err)
+		}
+		if capsule != reference.NextCapsule() {
+			t.Fatalf("sequence capsule %v does not match randomizer", i)
+		}
+	}
+
+	last, _ := sequence.GetCapsule(20)
+	if last != ahead {
+		t.Fatal("capsule changed between reads")
+	}
+
+	_, err := sequence.GetCapsule(-1)
+	if err == nil {
+		t.Fatal("negative index was read")
+	}
+}
+
+func TestLFSRRandomizerUsesWholeSeed(t *testing.T) {
+	deal := func(seed int64) []CapsuleColors {
+		randomizer, _ := NewCapsuleRandomizer(LFSRRandomizer, seed)
+		capsules := make([]CapsuleColors, 50)
+		for i := range capsules {
+			capsules[i] = randomizer.NextCapsule()
+		}
+		return capsules
+	}
+
+	same := func(first []CapsuleColors, second []CapsuleColors) bool {
+		for i := range first {
+			if first[i] != second[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// small seeds keep their value
+	if foldLFSRSeed(1234) != 1234 || foldLFSRSeed(0xFFFF) != 0xFFFF {
+		t.Fatal("small seed was changed")
+	}
+
+	// seeds only differing above the low 16 bits deal differently
+	if same(deal(1234), deal(1234+1<<40)) {
+		t.Fatal("high seed bits were ignored")
+	}
+	if same(deal(1<<16), deal(0)) || same(deal(5<<32), deal(0)) {
+		t.Fatal("multiple of 65536 dealt like seed 0")
+	}
+}
@@ -10,6 +10,7 @@ type SpaceContent int
 type SpaceLinkage int
 type SpaceColor int
 type NextIteration int
+type StreakOrientation int
 
 const (
 	Empty SpaceContent = iota
@@ -30,6 +31,7 @@ const (
 	Red
 	Blue
 	Yellow
+	Green
 )
 
 const (
@@ -38,6 +40,15 @@ const (
 	Fall
 )
 
+const (
+	Horizontal StreakOrientation = iota
+	Vertical
+	// down and to the right
+	DiagonalDown
+	// up and to the right
+	DiagonalUp
+)
+
 type Space struct {
 	Content SpaceContent
 	Linkage SpaceLinkage
@@ -49,10 +60,82 @@ type Coordinate struct {
 	x int
 }
 
+func (coord Coordinate) GetY() int {
+	return coord.y
+}
+
+func (coord Coordinate) GetX() int {
+	return coord.x
+}
+
+// a run of matching colors that clears
+// Start is the top space of a vertical streak and the left most space
+// of any other
+type Streak struct {
+	Orientation StreakOrientation
+	Start       Coordinate
+	Length      int
+	Color       SpaceColor
+	VirusCount  int
+}
+
+// get the coordinates of every space in the streak
+func (streak Streak) GetCoordinates() []Coordinate {
+	coords := make([]Coordinate, streak.Length)
+	dy, dx := streak.Orientation.getStep()
+	y, x := streak.Start.y, streak.Start.x
+	for i := range coords {
+		coords[i] = Coordinate{y, x}
+		y += dy
+		x += dx
+	}
+	return coords
+}
+
+// get the step from one space of a streak to the next
+func (orientation StreakOrientation) getStep() (int, int) {
+	switch orientation {
+	case Horizontal:
+		return 0, 1
+	case Vertical:
+		return 1, 0
+	case DiagonalDown:
+		return 1, 1
+	case DiagonalUp:
+		return -1, 1
+	}
+	return 0, 0
+}
+
+// the evaluation of a board iteration
+// Field has the next iteration for each space, Next the iteration type
+// and Streaks every streak that clears in order found, rows then columns,
+// then with diagonal rules down diagonals and up diagonals by start space.
+// ClearedVirusCount counts each cleared virus once even where streaks cross
+type IterationResult struct {
+	Field             [][]NextIteration
+	Next              NextIteration
+	Streaks           []Streak
+	ClearedVirusCount int
+}
+
+// get the color of each cleared streak
+func (result IterationResult) GetStreakColors() []SpaceColor {
+	colors := make([]SpaceColor, len(result.Streaks))
+	for i, streak := range result.Streaks {
+		colors[i] = streak.Color
+	}
+	return colors
+}
+
 // Playfield for drbreaktime game
-// can be arbitrarily sized
+// can be arbitrarily sized, boards up to 8x16 also keep their spaces
+// in bitboards for fast evaluation
 type PlayField struct {
-	spaces [][]Space
+	spaces    [][]Space
+	bitboards bitboardField
+	capsule   *ActiveCapsule
+	ruleset   Ruleset
 }
 
 // return an empty playfield
@@ -63,6 +146,7 @@ func NewPlayField(x int, y int) *PlayField {
 	for i := range field.spaces {
 		field.spaces[i] = make([]Space, x)
 	}
+	field.bitboards = newBitboardField(x, y)
 
 	return field
 }
@@ -195,13 +279,15 @@ func (field *PlayField) PutTwoLinkedSpacesAtCoordinate(y int, x int, coordSpace
 	return field.putSpaceAtCoordinate(linkedY, linkedX, linkedSpace)
 }
 
-// Clear the board
+// Clear the board, including any active capsule
 func (field *PlayField) ClearBoard() {
 	for _, row := range field.spaces {
 		for x := range row {
 			row[x] = Space{}
 		}
 	}
+	field.bitboards = newBitboardField(field.GetWidth(), field.GetHeight())
+	field.capsule = nil
 }
 
 func (field *PlayField) GetBottomRowIndex() int {
@@ -212,21 +298,41 @@ func (field *PlayField) GetBottomRowIndex() int {
 // returns a field sized 2D slice of next iteration for each square,
 // the next iteration type, and an array of colors for each cleared streak
 func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIteration, []SpaceColor) {
-	// initialize board iteration field to empty
-	nextIterationField := make([][]NextIteration, len(field.spaces))
-	for i := range nextIterationField {
-		nextIterationField[i] = make([]NextIteration, len(field.spaces[i]))
+	result := field.EvaluateBoardIterationStreaks()
+
+	if result.Next == Fall {
+		return result.Field, result.Next, nil
 	}
 
-	dockedField := field.generateDockedField()
+	return result.Field, result.Next, result.GetStreakColors()
+}
+
+// see what the next move and space states will be on iteration
+// like EvaluateBoardIteration, but every cleared streak is reported
+// separately with its position and contents
+// boards up to 8x16 are evaluated on bitboards, larger boards on slices
+func (field *PlayField) EvaluateBoardIterationStreaks() IterationResult {
+	return NewEvaluator().Evaluate(field)
+}
+
+// evaluate an iteration by walking the space slices, works for any board
+func (evaluator *Evaluator) evaluateSlices(field *PlayField) IterationResult {
+	// reset board iteration field to empty
+	nextIterationField := evaluator.resetIterationField(field)
+
+	result := IterationResult{nextIterationField, NoAction, nil, 0}
+
+	ruleset := field.GetRuleset()
+	dockedField := evaluator.generateDockedField(field)
 
 	undockedPieceFound := false
 
 	// look for falling pieces
 	for y := range dockedField {
 		for x, docked := range dockedField[y] {
-			if field.spaces[y][x].Content == Pill && !docked {
-				// undocked pill found
+			content := field.spaces[y][x].Content
+			if (content == Pill || (content == Virus && ruleset.LooseViruses)) && !docked {
+				// undocked pill, or virus that is not fixed, found
 				// mark undocked piece found and mark it as fall in the
 				// next iteration field
 				undockedPieceFound = true
@@ -238,7 +344,8 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 	// if we found a falling piece, we're done
 	// return the field and that the board has movement
 	if undockedPieceFound {
-		return nextIterationField, Fall, nil
+		result.Next = Fall
+		return result
 	}
 
 	// no falling pieces, check for clears
@@ -246,22 +353,17 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 
 	currentStreak := 0
 	currentColor := Uncolored
-	nextIter := NoAction
 
-	// look for rows with 4 or more consecutive color matches
-	colorSlice := make([]SpaceColor, 0)
+	// look for rows with MinMatchLength or more consecutive color matches
+	result.Streaks = evaluator.resetStreaks()
 	for y := range field.spaces {
 		x := 0
 		for {
 			if field.checkCoordinateInBounds(y, x) != nil {
 				// out of bounds
-				if currentStreak >= 4 && currentColor != Uncolored {
-					// have match stored, mark it and add to streak slice
-					nextIter = Clear
-					colorSlice = append(colorSlice, currentColor)
-					for i := x - currentStreak; i < x; i++ {
-						nextIterationField[y][i] = Clear
-					}
+				if currentStreak >= ruleset.MinMatchLength && currentColor != Uncolored {
+					// have match stored, mark it and add to streaks
+					field.markStreak(&result, Horizontal, Coordinate{y, x - currentStreak}, currentStreak, currentColor)
 				}
 
 				// clear the vars for new row
@@ -277,14 +379,10 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 					currentStreak += 1
 				} else {
 					// next piece is different
-					// check if we have a row of 4+
-					if currentStreak >= 4 && currentColor != Uncolored {
-						// have match stored, mark it and add to returned streak colors
-						nextIter = Clear
-						colorSlice = append(colorSlice, currentColor)
-						for i := x - currentStreak; i < x; i++ {
-							nextIterationField[y][i] = Clear
-						}
+					// check if we have a long enough row
+					if currentStreak >= ruleset.MinMatchLength && currentColor != Uncolored {
+						// have match stored, mark it and add to streaks
+						field.markStreak(&result, Horizontal, Coordinate{y, x - currentStreak}, currentStreak, currentColor)
 					}
 
 					// set the vars for the new streak
@@ -298,7 +396,7 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 		}
 	}
 
-	// look for cols with 4 or more consecutive color matches
+	// look for cols with MinMatchLength or more consecutive color matches
 	for x := range field.spaces[0] {
 		y := 0
 
@@ -309,13 +407,9 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 		for {
 			if field.checkCoordinateInBounds(y, x) != nil {
 				// out of bounds
-				if currentStreak >= 4 && currentColor != Uncolored {
-					// have match stored, mark it and add to color slice
-					nextIter = Clear
-					colorSlice = append(colorSlice, currentColor)
-					for i := y - currentStreak; i < y; i++ {
-						nextIterationField[i][x] = Clear
-					}
+				if currentStreak >= ruleset.MinMatchLength && currentColor != Uncolored {
+					// have match stored, mark it and add to streaks
+					field.markStreak(&result, Vertical, Coordinate{y - currentStreak, x}, currentStreak, currentColor)
 				}
 				// done with this row, break out of forever loop
 				break
@@ -326,14 +420,10 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 					currentStreak += 1
 				} else {
 					// next piece is different
-					// check if we have a row of 4+
-					if currentStreak >= 4 && currentColor != Uncolored {
-						// have match stored, mark it and add to streak slice
-						nextIter = Clear
-						colorSlice = append(colorSlice, currentColor)
-						for i := y - currentStreak; i < y; i++ {
-							nextIterationField[i][x] = Clear
-						}
+					// check if we have a long enough row
+					if currentStreak >= ruleset.MinMatchLength && currentColor != Uncolored {
+						// have match stored, mark it and add to streaks
+						field.markStreak(&result, Vertical, Coordinate{y - currentStreak, x}, currentStreak, currentColor)
 					}
 
 					// set the vars for the new streak
@@ -347,7 +437,71 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 		}
 	}
 
-	return nextIterationField, nextIter, colorSlice
+	// look for diagonals with MinMatchLength or more consecutive color matches
+	if ruleset.Diagonals {
+		field.markDiagonalStreaks(&result, DiagonalDown, ruleset.MinMatchLength)
+		field.markDiagonalStreaks(&result, DiagonalUp, ruleset.MinMatchLength)
+	}
+
+	// count cleared viruses, streaks can share a space so count from the field
+	if result.Next == Clear {
+		for y, row := range nextIterationField {
+			for x, iter := range row {
+				if iter == Clear && field.spaces[y][x].Content == Virus {
+					result.ClearedVirusCount += 1
+				}
+			}
+		}
+	}
+
+	// keep any room the streaks grew for the next evaluation
+	evaluator.streaks = result.Streaks
+	return result
+}
+
+// mark the spaces of a streak as Clear and add it to the result
+func (field *PlayField) markStreak(result *IterationResult, orientation StreakOrientation,
+	start Coordinate, length int, color SpaceColor) {
+	streak := Streak{orientation, start, length, color, 0}
+
+	dy, dx := orientation.getStep()
+	y, x := start.y, start.x
+	for i := 0; i < length; i++ {
+		result.Field[y][x] = Clear
+		if field.spaces[y][x].Content == Virus {
+			streak.VirusCount += 1
+		}
+
+		y += dy
+		x += dx
+	}
+
+	result.Next = Clear
+	result.Streaks = append(result.Streaks, streak)
+}
+
+// mark every diagonal streak of an orientation, going through spaces in
+// row order and measuring the runs that start at each
+func (field *PlayField) markDiagonalStreaks(result *IterationResult, orientation StreakOrientation, minMatchLength int) {
+	dy, dx := orientation.getStep()
+
+	for y, row := range field.spaces {
+		for x, space := range row {
+			if space.Color == Uncolored {
+				continue
+			}
+
+			// the space before this one in the line has the color, not a start
+			if field.checkCoordinateInBounds(y-dy, x-dx) == nil && field.spaces[y-dy][x-dx].Color == space.Color {
+				continue
+			}
+
+			length := 1 + field.countColorRun(y, x, dy, dx, space.Color)
+			if length >= minMatchLength {
+				field.markStreak(result, orientation, Coordinate{y, x}, length, space.Color)
+			}
+		}
+	}
 }
 
 // iterate changes through the board
@@ -355,8 +509,13 @@ func (field *PlayField) EvaluateBoardIteration() ([][]NextIteration, NextIterati
 // and should cause a panic level reaction
 func (field *PlayField) IterateBoard() error {
 	log.Trace().Msg("Entering IterateBoard()")
-	iterField, nextIter, _ := field.EvaluateBoardIteration()
+	_, err := field.StepBoard()
+	return err
+}
 
+// apply an evaluated iteration to the board
+// iterField and nextIter must come from EvaluateBoardIteration on this board
+func (field *PlayField) applyIteration(iterField [][]NextIteration, nextIter NextIteration) error {
 	// no changes means nothing to iterate
 	if nextIter == NoAction {
 		log.Debug().Msg("no action needed for iterate")
@@ -480,6 +639,7 @@ func (field *PlayField) putSpaceAtCoordinate(y int, x int, space Space) error {
 	}
 
 	field.spaces[y][x] = space
+	field.bitboards.setSpace(y, x, space)
 	return nil
 }
 
@@ -544,23 +704,21 @@ func (field *PlayField) clearSpace(y int, x int) error {
 	return nil
 }
 
-func (field *PlayField) generateDockedField() [][]bool {
-	// initialize docked field to empty
-	dockedField := make([][]bool, len(field.spaces))
-	for i := range dockedField {
-		dockedField[i] = make([]bool, len(field.spaces[i]))
-	}
+func (evaluator *Evaluator) generateDockedField(field *PlayField) [][]bool {
+	// reset docked field to empty
+	dockedField := evaluator.resetDockedField(field)
 
-	// create a queue for pieces to evaluate for dockedness
-	dockedCheckQueue := make([]Coordinate, 0)
+	// reuse the queue for pieces to evaluate for dockedness
+	dockedCheckQueue := evaluator.queue[:0]
 
 	// seed queue with virii and pieces on bottom
+	looseViruses := field.GetRuleset().LooseViruses
 	for y := range dockedField {
 		for x := range dockedField[y] {
 			// iterate through all pieces looking for virii or bottom row
 			space, _ := field.GetSpaceAtCoordinate(y, x)
-			if space.Content == Virus {
-				// all virii are docked
+			if space.Content == Virus && !looseViruses {
+				// all fixed virii are docked
 				dockedCheckQueue = append(dockedCheckQueue, Coordinate{y, x})
 			} else if y == field.GetBottomRowIndex() && space.Content != Empty {
 				// piece resting on bottom
@@ -569,10 +727,9 @@ func (field *PlayField) generateDockedField() [][]bool {
 		}
 	}
 
-	for len(dockedCheckQueue) > 0 {
-		// get the space and remove it from the queue
-		dockedSpace := dockedCheckQueue[0]
-		dockedCheckQueue = dockedCheckQueue[1:]
+	for head := 0; head < len(dockedCheckQueue); head++ {
+		// get the next space in the queue
+		dockedSpace := dockedCheckQueue[head]
 
 		// check coordinate in bounds
 		if field.checkCoordinateInBounds(dockedSpace.y, dockedSpace.x) != nil {
@@ -606,5 +763,7 @@ func (field *PlayField) generateDockedField() [][]bool {
 		}
 	}
 
+	// keep any room the queue grew for the next evaluation
+	evaluator.queue = dockedCheckQueue
 	return dockedField
 }
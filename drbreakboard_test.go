@@ -1,6 +1,7 @@
 package drbreakboard
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -35,7 +36,7 @@ func TestBoardCreation(t *testing.T) {
 func TestClearChecking(t *testing.T) {
 	field := NewPlayField(8, 16)
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	bottomRow := field.GetBottomRowIndex()
 
@@ -44,11 +45,11 @@ func TestClearChecking(t *testing.T) {
 	field.PutSpaceAtCoordinateIfEmpty(bottomRow, 2, Space{Pill, Unlinked, Blue})
 	field.PutSpaceAtCoordinateIfEmpty(bottomRow, 3, Space{Pill, Unlinked, Blue})
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	iterField, nextIter, _ := field.EvaluateBoardIteration()
 
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 
 	if nextIter != Clear {
 		t.Fatal("clear iteration returned no changes")
@@ -64,7 +65,7 @@ func TestClearChecking(t *testing.T) {
 
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
 
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 
 	if nextIter == NoAction {
 		t.Fatal("clear iteration returned no changes")
@@ -78,17 +79,17 @@ func TestClearChecking(t *testing.T) {
 func TestSinglePillStackFallChecking(t *testing.T) {
 	field := NewPlayField(8, 16)
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	bottomRow := field.GetBottomRowIndex()
 
 	field.PutSpaceAtCoordinateIfEmpty(bottomRow-1, 0, Space{Pill, Unlinked, Blue})
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	iterField, nextIter, _ := field.EvaluateBoardIteration()
 
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 
 	if nextIter != Fall {
 		t.Fatal("fall iteration returned no changes")
@@ -100,11 +101,11 @@ func TestSinglePillStackFallChecking(t *testing.T) {
 
 	field.PutSpaceAtCoordinateIfEmpty(bottomRow-2, 0, Space{Pill, Unlinked, Blue})
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
 
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 
 	if nextIter != Fall {
 		t.Fatal("fall iteration returned no changes")
@@ -116,11 +117,11 @@ func TestSinglePillStackFallChecking(t *testing.T) {
 
 	field.PutSpaceAtCoordinateIfEmpty(bottomRow, 0, Space{Pill, Unlinked, Blue})
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
 
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 
 	if nextIter != NoAction {
 		t.Fatal("stack from bottom had a change")
@@ -130,7 +131,7 @@ func TestSinglePillStackFallChecking(t *testing.T) {
 func TestLinkedSpaceIteration(t *testing.T) {
 	field := NewPlayField(8, 16)
 
-	DrawBoard(field)
+	logBoard(t, field)
 
 	bottomRow := field.GetBottomRowIndex()
 
@@ -144,9 +145,9 @@ func TestLinkedSpaceIteration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("put linked pill err: %v", err)
 	}
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ := field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Fall {
 		t.Fatal("fall iteration returned no changes")
 	}
@@ -160,9 +161,9 @@ func TestLinkedSpaceIteration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("put linked pill err: %v", err)
 	}
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Fall {
 		t.Fatal("fall iteration returned no changes")
 	}
@@ -172,9 +173,9 @@ func TestLinkedSpaceIteration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("put linked pill err: %v", err)
 	}
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != NoAction {
 		t.Fatal("fall iteration should have no changes")
 	}
@@ -188,9 +189,9 @@ func TestLinkedSpaceIteration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not place piece %v", err)
 	}
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Clear {
 		t.Fatal("col should be detected")
 	}
@@ -222,9 +223,9 @@ func TestVirusSpaceIteration(t *testing.T) {
 	}
 
 	// make sure there are no changes
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ := field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != NoAction {
 		t.Fatal("no changes should have been found")
 	}
@@ -235,9 +236,9 @@ func TestVirusSpaceIteration(t *testing.T) {
 	if err != nil {
 		t.Fatalf("could not place linked piece %v", err)
 	}
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Clear {
 		t.Fatal("clear should have caused change")
 	}
@@ -259,9 +260,9 @@ func TestIterationExecution(t *testing.T) {
 	field.PutTwoLinkedSpacesAtCoordinate(bottomRow-2, 3, space, linkedSpace)
 	field.PutTwoLinkedSpacesAtCoordinate(bottomRow-3, 3, space, linkedSpace)
 
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ := field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Clear {
 		t.Fatal("clear should be next iteration")
 	}
@@ -271,9 +272,9 @@ func TestIterationExecution(t *testing.T) {
 	}
 
 	// iterate should leave two uncleared stacked blocks to fall 2 spots
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Fall {
 		t.Fatal("fall should be next iteration")
 	}
@@ -283,9 +284,9 @@ func TestIterationExecution(t *testing.T) {
 	}
 
 	// run second fall
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != Fall {
 		t.Fatal("fall should be next iteration")
 	}
@@ -294,10 +295,230 @@ func TestIterationExecution(t *testing.T) {
 		t.Fatalf("board iterate errored, %v", err)
 	}
 
-	DrawBoard(field)
+	logBoard(t, field)
 	iterField, nextIter, _ = field.EvaluateBoardIteration()
-	DrawNextIteration(iterField)
+	logNextIteration(t, iterField)
 	if nextIter != NoAction {
 		t.Fatal("no action should be next iteration")
 	}
 }
+
+func TestStreakEvaluation(t *testing.T) {
+	field := NewPlayField(8, 16)
+	virus, _ := MakeVirus(Red)
+	bottomRow := field.GetBottomRowIndex()
+
+	// a single 5 long vertical streak of 3 viruses and 2 pills
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow, 2, virus)
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-1, 2, virus)
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-2, 2, virus)
+	space, linkedSpace, _ := MakeLinkedPillSpaces(Down, Red, Red)
+	field.PutTwoLinkedSpacesAtCoordinate(bottomRow-4, 2, space, linkedSpace)
+
+	result := field.EvaluateBoardIterationStreaks()
+	logNextIteration(t, result.Field)
+	if result.Next != Clear {
+		t.Fatal("vertical streak should clear")
+	}
+	if len(result.Streaks) != 1 {
+		t.Fatalf("expected one streak, got %v", len(result.Streaks))
+	}
+	streak := result.Streaks[0]
+	if streak.Orientation != Vertical || streak.Length != 5 || streak.Color != Red || streak.VirusCount != 3 {
+		t.Fatalf("unexpected streak %v", streak)
+	}
+	if streak.Start.GetY() != bottomRow-4 || streak.Start.GetX() != 2 {
+		t.Fatalf("streak started at %v", streak.Start)
+	}
+
+	// add a horizontal streak crossing the top of the vertical one
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-4, 0, Space{Pill, Unlinked, Red})
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-4, 1, Space{Pill, Unlinked, Red})
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-4, 3, Space{Pill, Unlinked, Red})
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-5, 0, virus)
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-5, 1, virus)
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-5, 3, virus)
+	for y := bottomRow - 3; y <= bottomRow; y++ {
+		field.PutSpaceAtCoordinateIfEmpty(y, 0, Space{Pill, Unlinked, Blue})
+		field.PutSpaceAtCoordinateIfEmpty(y, 1, Space{Pill, Unlinked, Yellow})
+		field.PutSpaceAtCoordinateIfEmpty(y, 3, Space{Pill, Unlinked, Yellow})
+	}
+	field.PutSpaceAtCoordinateIfEmpty(bottomRow-3, 1, Space{Pill, Unlinked, Blue})
+
+	logBoard(t, field)
+	result = field.EvaluateBoardIterationStreaks()
+	logNextIteration(t, result.Field)
+	iterField, nextIter, colors := field.EvaluateBoardIteration()
+	if nextIter != result.Next || len(colors) != len(result.Streaks) || iterField[bottomRow-4][0] != Clear {
+		t.Fatal("EvaluateBoardIteration disagrees with streak evaluation")
+	}
+
+	horizontal := 0
+	vertical := 0
+	for _, streak := range result.Streaks {
+		switch streak.Orientation {
+		case Horizontal:
+			horizontal += 1
+			if streak.Color != Red || streak.Length != 4 || streak.VirusCount != 0 {
+				t.Fatalf("unexpected horizontal streak %v", streak)
+			}
+		case Vertical:
+			vertical += 1
+		}
+	}
+	if horizontal != 1 || vertical < 1 {
+		t.Fatalf("expected crossing streaks, got %v", result.Streaks)
+	}
+}
+
+func TestDiagonalStreaks(t *testing.T) {
+	tests := []struct {
+		name      string
+		diagonals bool
+		board     string
+		streaks   []Streak
+		viruses   int
+		after     string
+	}{
+		{
+			name:      "down diagonal",
+			diagonals: true,
+			board: `
+				XXX XXX XXX XXX XXX
+				VRX XXX XXX XXX XXX
+				XXX VRX XXX XXX XXX
+				XXX XXX VRX XXX XXX
+				XXX XXX XXX VRX XXX`,
+			streaks: []Streak{{DiagonalDown, Coordinate{1, 0}, 4, Red, 4}},
+			viruses: 4,
+			after: `
+				XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX`,
+		},
+		{
+			name:      "up diagonal sharing a space with a row",
+			diagonals: true,
+			board: `
+				XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX VBX
+				XXX XXX XXX XXX VBX XXX
+				XXX PYD XXX VBX XXX XXX
+				VBX PBU VBX VBX XXX XXX`,
+			streaks: []Streak{
+				{Horizontal, Coordinate{4, 0}, 4, Blue, 3},
+				{DiagonalUp, Coordinate{4, 2}, 4, Blue, 4},
+			},
+			viruses: 6,
+			after: `
+				XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX
+				XXX PYX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX`,
+		},
+		{
+			name:      "row column and both diagonals through one space",
+			diagonals: true,
+			board: `
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX VRX XXX
+				XXX XXX VRX VRX VRX XXX XXX
+				XXX XXX VRX VRX VRX VRX XXX
+				XXX XXX VRX VRX VRX XXX XXX
+				XXX XXX XXX VRX XXX VRX XXX
+				XXX XXX XXX XXX XXX XXX XXX`,
+			streaks: []Streak{
+				{Horizontal, Coordinate{3, 2}, 4, Red, 4},
+				{Vertical, Coordinate{2, 3}, 4, Red, 4},
+				{DiagonalDown, Coordinate{2, 2}, 4, Red, 4},
+				{DiagonalUp, Coordinate{4, 2}, 4, Red, 4},
+			},
+			viruses: 13,
+			after: `
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX`,
+		},
+		{
+			name:      "same board without diagonal rules",
+			diagonals: false,
+			board: `
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX VRX XXX
+				XXX XXX VRX VRX VRX XXX XXX
+				XXX XXX VRX VRX VRX VRX XXX
+				XXX XXX VRX VRX VRX XXX XXX
+				XXX XXX XXX VRX XXX VRX XXX
+				XXX XXX XXX XXX XXX XXX XXX`,
+			streaks: []Streak{
+				{Horizontal, Coordinate{3, 2}, 4, Red, 4},
+				{Vertical, Coordinate{2, 3}, 4, Red, 4},
+			},
+			viruses: 7,
+			after: `
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX XXX XXX XXX VRX XXX
+				XXX XXX VRX XXX VRX XXX XXX
+				XXX XXX XXX XXX XXX XXX XXX
+				XXX XXX VRX XXX VRX XXX XXX
+				XXX XXX XXX XXX XXX VRX XXX
+				XXX XXX XXX XXX XXX XXX XXX`,
+		},
+		{
+			name:      "diagonal of linked pill halves",
+			diagonals: true,
+			board: `
+				PYR PBL XXX XXX XXX
+				XXX PYR PRL XXX XXX
+				XXX XXX PYR PBL XXX
+				XXX XXX XXX PYR PRL`,
+			streaks: []Streak{{DiagonalDown, Coordinate{0, 0}, 4, Yellow, 0}},
+			viruses: 0,
+			after: `
+				XXX PBX XXX XXX XXX
+				XXX XXX PRX XXX XXX
+				XXX XXX XXX PBX XXX
+				XXX XXX XXX XXX PRX`,
+		},
+	}
+
+	for _, test := range tests {
+		field, err := ParsePlayField(test.board)
+		if err != nil {
+			t.Fatalf("%v: board did not parse %v", test.name, err)
+		}
+		field.ruleset = ClassicRuleset
+		field.ruleset.Diagonals = test.diagonals
+
+		// bitboards and slices must agree
+		for _, result := range []IterationResult{NewEvaluator().Evaluate(field), NewEvaluator().evaluateSlices(field)} {
+			if result.Next != Clear || !reflect.DeepEqual(result.Streaks, test.streaks) {
+				t.Fatalf("%v: found streaks %v", test.name, result.Streaks)
+			}
+			if result.ClearedVirusCount != test.viruses {
+				t.Fatalf("%v: cleared %v viruses", test.name, result.ClearedVirusCount)
+			}
+		}
+
+		_, _, colors := field.EvaluateBoardIteration()
+		if len(colors) != len(test.streaks) {
+			t.Fatalf("%v: reported %v streak colors", test.name, len(colors))
+		}
+
+		if err = field.IterateBoard(); err != nil {
+			t.Fatalf("%v: iterate failed %v", test.name, err)
+		}
+		after, _ := ParsePlayField(test.after)
+		if FormatPlayField(field) != FormatPlayField(after) {
+			logBoard(t, field)
+			t.Fatalf("%v: board after clearing differs", test.name)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package drbreakboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// version of the replay file format
+const ReplayVersion = 1
+
+// an action the player made on a game frame
+type ReplayInput struct {
+	Tick   uint64 `json:"tick"`
+	Action Action `json:"action"`
+}
+
+// a recorded game, the config it started from and every player input
+// replaying the inputs from the config must reach FinalHash at FinalTick
+type Replay struct {
+	Version   int           `json:"version"`
+	Config    GameConfig    `json:"config"`
+	Inputs    []ReplayInput `json:"inputs"`
+	FinalTick uint64        `json:"finalTick"`
+	FinalHash uint64        `json:"finalHash,string"`
+}
+
+// write a replay as json
+func WriteReplay(w io.Writer, replay Replay) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(replay)
+}
+
+// read a replay written by WriteReplay
+func ReadReplay(r io.Reader) (Replay, error) {
+	var replay Replay
+	if err := json.NewDecoder(r).Decode(&replay); err != nil {
+		return Replay{}, err
+	}
+
+	if replay.Version != ReplayVersion {
+		return Replay{}, fmt.Errorf("unsupported replay version %v", replay.Version)
+	}
+
+	return replay, nil
+}
+
+// plays a game while recording its inputs
+type ReplayRecorder struct {
+	game   *Game
+	replay Replay
+}
+
+// start a new game and record it
+func NewReplayRecorder(config GameConfig) (*ReplayRecorder, error) {
+	game, err := NewGame(config)
+	if err != nil {
+		return nil, err
+	}
+
+	recorder := &ReplayRecorder{game: game}
+	recorder.replay.Version = ReplayVersion
+	recorder.replay.Config = config
+	return recorder, nil
+}
+
+func (recorder *ReplayRecorder) GetGame() *Game {
+	return recorder.game
+}
+
+// tick the game like Game.Tick and record the actions
+func (recorder *ReplayRecorder) Tick(actions ...Action) error {
+	frame := recorder.game.GetFrame()
+	err := recorder.game.Tick(actions...)
+
+	// finished games do not advance, nothing to record
+	if recorder.game.GetFrame() == frame {
+		return err
+	}
+
+	for _, action := range actions {
+		if action != ActionNone {
+			recorder.replay.Inputs = append(recorder.replay.Inputs,
+				ReplayInput{recorder.game.GetFrame(), action})
+		}
+	}
+
+	return err
+}
+
+// get the replay of the game so far
+func (recorder *ReplayRecorder) GetReplay() Replay {
+	replay := recorder.replay
+	replay.Inputs = append([]ReplayInput(nil), recorder.replay.Inputs...)
+	replay.FinalTick = recorder.game.GetFrame()
+	replay.FinalHash = recorder.game.GetField().Hash()
+	return replay
+}
+
+// re-runs a replay one tick at a time
+type ReplayPlayer struct {
+	replay Replay
+	game   *Game
+	next   int
+}
+
+// start the game a replay was recorded from
+func NewReplayPlayer(replay Replay) (*ReplayPlayer, error) {
+	for i, input := range replay.Inputs {
+		if input.Tick == 0 || input.Tick > replay.FinalTick {
+			return nil, fmt.Errorf("replay input %v is outside the replay", i)
+		}
+		if i > 0 && input.Tick < replay.Inputs[i-1].Tick {
+			return nil, fmt.Errorf("replay input %v is out of order", i)
+		}
+	}
+
+	game, err := NewGame(replay.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayPlayer{replay: replay, game: game}, nil
+}
+
+func (player *ReplayPlayer) GetGame() *Game {
+	return player.game
+}
+
+// check if the replay has reached its final tick
+func (player *ReplayPlayer) IsDone() bool {
+	return player.game.GetFrame() >= player.replay.FinalTick
+}
+
+// tick the game once with the inputs recorded for that tick
+// returns false once the replay is done
+func (player *ReplayPlayer) Step() (bool, error) {
+	if player.IsDone() {
+		return false, nil
+	}
+
+	tick := player.game.GetFrame() + 1
+	actions := make([]Action, 0)
+	for player.next < len(player.replay.Inputs) && player.replay.Inputs[player.next].Tick == tick {
+		actions = append(actions, player.replay.Inputs[player.next].Action)
+		player.next += 1
+	}
+
+	frame := player.game.GetFrame()
+	err := player.game.Tick(actions...)
+	if err != nil {
+		return false, err
+	}
+
+	if player.game.GetFrame() == frame {
+		return false, errors.New("replay continues after the game ended")
+	}
+
+	return true, nil
+}
+
+// re-run a replay to its final tick and check the board matches
+func VerifyReplay(replay Replay) error {
+	player, err := NewReplayPlayer(replay)
+	if err != nil {
+		return err
+	}
+
+	for {
+		stepped, err := player.Step()
+		if err != nil {
+			return err
+		}
+		if !stepped {
+			break
+		}
+	}
+
+	hash := player.game.GetField().Hash()
+	if hash != replay.FinalHash {
+		return fmt.Errorf("replay board hash %016x does not match recorded %016x", hash, replay.FinalHash)
+	}
+
+	return nil
+}
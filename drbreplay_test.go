@@ -0,0 +1,144 @@
+package drbreakboard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// record a game driven by a fixed input pattern
+func recordTestReplay(t *testing.T, ticks int) Replay {
+	config := GameConfig{Level: 8, Speed: Med, Seed: 2024, Randomizer: LFSRRandomizer}
+	recorder, err := NewReplayRecorder(config)
+	if err != nil {
+		t.Fatalf("new recorder failed %v", err)
+	}
+
+	script := []Action{ActionMoveLeft, ActionMoveLeft, ActionRotateClockwise, ActionSoftDrop,
+		ActionMoveRight, ActionRotateCounterClockwise, ActionSoftDrop}
+	for frame := 0; frame < ticks; frame++ {
+		if frame%4 == 0 {
+			err = recorder.Tick(script[(frame/4)%len(script)], ActionNone)
+		} else {
+			err = recorder.Tick()
+		}
+		if err != nil {
+			t.Fatalf("recorded tick failed %v", err)
+		}
+	}
+
+	return recorder.GetReplay()
+}
+
+func TestReplayVerify(t *testing.T) {
+	replay := recordTestReplay(t, 1500)
+
+	if len(replay.Inputs) == 0 || replay.FinalTick == 0 {
+		t.Fatal("nothing recorded")
+	}
+	for _, input := range replay.Inputs {
+		if input.Action == ActionNone {
+			t.Fatal("recorded an empty action")
+		}
+	}
+
+	if err := VerifyReplay(replay); err != nil {
+		t.Fatalf("replay did not verify %v", err)
+	}
+
+	// a changed input desyncs the replay
+	changed := replay
+	changed.Inputs = append([]ReplayInput(nil), replay.Inputs...)
+	changed.Inputs[0].Action = ActionMoveRight
+	if VerifyReplay(changed) == nil {
+		t.Fatal("changed replay verified")
+	}
+
+	changed = replay
+	changed.FinalHash += 1
+	if VerifyReplay(changed) == nil {
+		t.Fatal("wrong hash verified")
+	}
+}
+
+func TestReplayPlayerStates(t *testing.T) {
+	config := GameConfig{Level: 3, Speed: Hi, Seed: 7}
+	recorder, _ := NewReplayRecorder(config)
+
+	states := make([]string, 0)
+	for frame := 0; frame < 400; frame++ {
+		if frame%5 == 0 {
+			recorder.Tick(ActionSoftDrop)
+		} else {
+			recorder.Tick()
+		}
+		data, _ := recorder.GetGame().GetField().MarshalJSON()
+		states = append(states, string(data))
+
+		if recorder.GetGame().IsOver() {
+			break
+		}
+	}
+
+	player, err := NewReplayPlayer(recorder.GetReplay())
+	if err != nil {
+		t.Fatalf("new player failed %v", err)
+	}
+
+	for i := 0; ; i++ {
+		stepped, err := player.Step()
+		if err != nil {
+			t.Fatalf("step failed %v", err)
+		}
+		if !stepped {
+			if i != len(states) {
+				t.Fatalf("replay stopped after %v ticks", i)
+			}
+			break
+		}
+
+		data, _ := player.GetGame().GetField().MarshalJSON()
+		if string(data) != states[i] {
+			t.Fatalf("replay state differs at tick %v", i+1)
+		}
+	}
+}
+
+func TestReplayFile(t *testing.T) {
+	replay := recordTestReplay(t, 300)
+
+	var buffer bytes.Buffer
+	if err := WriteReplay(&buffer, replay); err != nil {
+		t.Fatalf("write replay failed %v", err)
+	}
+	if !strings.Contains(buffer.String(), `"action": "cw"`) {
+		t.Fatal("actions not written by name")
+	}
+
+	read, err := ReadReplay(&buffer)
+	if err != nil {
+		t.Fatalf("read replay failed %v", err)
+	}
+	if read.FinalHash != replay.FinalHash || len(read.Inputs) != len(replay.Inputs) || read.Config != replay.Config {
+		t.Fatal("read replay differs from written")
+	}
+	if err = VerifyReplay(read); err != nil {
+		t.Fatalf("read replay did not verify %v", err)
+	}
+
+	_, err = ReadReplay(strings.NewReader(`{"version": 99}`))
+	if err == nil {
+		t.Fatal("unknown version read")
+	}
+	_, err = ReadReplay(strings.NewReader(`{"version": 1, "inputs": [{"tick": 1, "action": "jump"}]}`))
+	if err == nil {
+		t.Fatal("unknown action read")
+	}
+
+	// inputs out of order are rejected
+	read.Inputs[0], read.Inputs[1] = read.Inputs[1], read.Inputs[0]
+	read.Inputs[0].Tick, read.Inputs[1].Tick = 20, 10
+	if _, err = NewReplayPlayer(read); err == nil {
+		t.Fatal("out of order inputs played")
+	}
+}
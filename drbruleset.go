@@ -0,0 +1,91 @@
+package drbreakboard
+
+import (
+	"errors"
+)
+
+// fewest and most colors a ruleset can play with, one color lines up
+// too easily to generate most virus levels
+const (
+	MinColorCount = 2
+	MaxColorCount = 4
+)
+
+// shortest match a ruleset can clear on, viruses are placed so they never
+// line up one short of a match, which needs at least two in a line
+const MinRulesetMatchLength = 3
+
+// order colors come into play, a ruleset with ColorCount n uses the
+// first n. also the order virus colors cycle and capsule pairs are listed
+var rulesetColorOrder = []SpaceColor{Yellow, Red, Blue, Green}
+
+// rules for evaluating a board and generating its viruses and capsules
+type Ruleset struct {
+	// number of same colored spaces in a line that clear
+	MinMatchLength int `json:"minMatchLength"`
+	// number of colors in play, from Yellow, Red, Blue and Green in order
+	ColorCount int `json:"colorCount"`
+	// diagonal lines clear as well as rows and columns
+	Diagonals bool `json:"diagonals"`
+	// viruses with nothing under them fall like pills, otherwise
+	// viruses never fall
+	LooseViruses bool `json:"looseViruses"`
+}
+
+// classic rules, 4 in a row of 3 colors with viruses fixed in place
+// boards made with NewPlayField use these, and zero fields of any
+// ruleset take their classic value
+var ClassicRuleset = Ruleset{
+	MinMatchLength: 4,
+	ColorCount:     3,
+	Diagonals:      false,
+	LooseViruses:   false,
+}
+
+// return an empty playfield that plays by a ruleset
+func NewPlayFieldWithRuleset(x int, y int, ruleset Ruleset) (*PlayField, error) {
+	if err := ruleset.check(); err != nil {
+		return nil, err
+	}
+
+	field := NewPlayField(x, y)
+	field.ruleset = ruleset
+	return field, nil
+}
+
+// get the rules the board plays by
+func (field *PlayField) GetRuleset() Ruleset {
+	return field.ruleset.orClassic()
+}
+
+// get the colors in play
+func (ruleset Ruleset) GetColors() []SpaceColor {
+	ruleset = ruleset.orClassic()
+	return append([]SpaceColor(nil), rulesetColorOrder[:ruleset.ColorCount]...)
+}
+
+// fill zero match length and color count with their classic values
+func (ruleset Ruleset) orClassic() Ruleset {
+	if ruleset.MinMatchLength == 0 {
+		ruleset.MinMatchLength = ClassicRuleset.MinMatchLength
+	}
+	if ruleset.ColorCount == 0 {
+		ruleset.ColorCount = ClassicRuleset.ColorCount
+	}
+	return ruleset
+}
+
+// error if the ruleset cannot be played
+func (ruleset Ruleset) check() error {
+	ruleset = ruleset.orClassic()
+
+	if ruleset.MinMatchLength < MinRulesetMatchLength {
+		return errors.New("ruleset match length is too short")
+	}
+
+	if ruleset.ColorCount < MinColorCount || ruleset.ColorCount > MaxColorCount {
+		return errors.New("ruleset color count is out of range")
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package drbreakboard
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+)
+
+func TestNewPlayFieldWithRuleset(t *testing.T) {
+	field, err := NewPlayFieldWithRuleset(8, 16, Ruleset{5, 4, false, false})
+	if err != nil {
+		t.Fatalf("valid ruleset failed %v", err)
+	}
+	if field.GetRuleset().MinMatchLength != 5 || len(field.GetRuleset().GetColors()) != 4 {
+		t.Fatalf("board has ruleset %+v", field.GetRuleset())
+	}
+	if field.Clone().GetRuleset() != field.GetRuleset() {
+		t.Fatal("clone lost the ruleset")
+	}
+	if field.Equal(NewPlayField(8, 16)) {
+		t.Fatal("boards with different rulesets are equal")
+	}
+
+	if NewPlayField(8, 16).GetRuleset() != ClassicRuleset || (&PlayField{}).GetRuleset() != ClassicRuleset {
+		t.Fatal("default ruleset is not classic")
+	}
+
+	// zero fields take their classic value
+	partial := []struct {
+		ruleset  Ruleset
+		expected Ruleset
+	}{
+		{Ruleset{MinMatchLength: 5}, Ruleset{5, 3, false, false}},
+		{Ruleset{ColorCount: 4}, Ruleset{4, 4, false, false}},
+		{Ruleset{Diagonals: true}, Ruleset{4, 3, true, false}},
+	}
+	for _, test := range partial {
+		field, err := NewPlayFieldWithRuleset(8, 16, test.ruleset)
+		if err != nil || field.GetRuleset() != test.expected {
+			t.Fatalf("ruleset %+v played as %+v, %v", test.ruleset, field.GetRuleset(), err)
+		}
+	}
+
+	var config GameConfig
+	json.Unmarshal([]byte(`{"ruleset":{"minMatchLength":5,"colorCount":3}}`), &config)
+	if field, _ := NewPlayFieldWithRuleset(8, 16, config.Ruleset); field.GetRuleset() != (Ruleset{5, 3, false, false}) {
+		t.Fatalf("json ruleset played as %+v", field.GetRuleset())
+	}
+
+	bad := []Ruleset{
+		{2, 3, false, false},
+		{-1, 3, false, false},
+		{4, 1, false, false},
+		{4, MaxColorCount + 1, false, false},
+	}
+	for _, ruleset := range bad {
+		if _, err := NewPlayFieldWithRuleset(8, 16, ruleset); err == nil {
+			t.Fatalf("ruleset %+v made a board", ruleset)
+		}
+	}
+}
+
+func TestRulesetMatchLength(t *testing.T) {
+	tests := []struct {
+		name     string
+		width    int
+		length   int
+		matches  []int
+		clearing int
+	}{
+		{"classic four clears", 8, 4, []int{4}, 4},
+		{"classic three stays", 8, 4, []int{3}, 0},
+		{"hard four stays", 8, 5, []int{4}, 0},
+		{"hard five clears", 8, 5, []int{5}, 5},
+		{"three match", 8, 3, []int{3, 1, 3}, 6},
+		// wider than a bitboard, evaluated on slices
+		{"wide hard five clears", 12, 5, []int{5, 1, 6}, 11},
+		{"wide hard four stays", 12, 5, []int{4, 1, 4}, 0},
+	}
+
+	for _, test := range tests {
+		ruleset := ClassicRuleset
+		ruleset.MinMatchLength = test.length
+		field, _ := NewPlayFieldWithRuleset(test.width, 4, ruleset)
+
+		// runs of viruses along the bottom row, alternating red and blue
+		x := 0
+		for i, run := range test.matches {
+			color := Red
+			if i%2 == 1 {
+				color = Blue
+			}
+			for j := 0; j < run; j++ {
+				field.ForcePutSingleSpaceIntoBoard(3, x, Space{Virus, Unlinked, color})
+				x += 1
+			}
+		}
+
+		result := field.EvaluateBoardIterationStreaks()
+		if result.ClearedVirusCount != test.clearing {
+			t.Fatalf("%v: cleared %v viruses", test.name, result.ClearedVirusCount)
+		}
+	}
+}
+
+func TestRulesetLooseViruses(t *testing.T) {
+	for _, width := range []int{8, 12} {
+		ruleset := ClassicRuleset
+		ruleset.LooseViruses = true
+		field, _ := NewPlayFieldWithRuleset(width, 4, ruleset)
+		field.ForcePutSingleSpaceIntoBoard(1, 2, Space{Virus, Unlinked, Red})
+		field.ForcePutSingleSpaceIntoBoard(3, 5, Space{Virus, Unlinked, Blue})
+
+		result := field.EvaluateBoardIterationStreaks()
+		if result.Next != Fall || result.Field[1][2] != Fall || result.Field[3][5] != NoAction {
+			t.Fatalf("width %v loose virus did not fall alone", width)
+		}
+
+		report, err := field.ResolveUntilStable()
+		if err != nil {
+			t.Fatalf("resolve failed %v", err)
+		}
+		if space, _ := field.GetSpaceAtCoordinate(3, 2); space.Content != Virus || report.ChainDepth != 0 {
+			t.Fatalf("width %v virus did not land", width)
+		}
+	}
+}
+
+func TestRulesetFourColors(t *testing.T) {
+	party := Ruleset{4, 4, false, false}
+
+	field, _ := NewPlayFieldWithRuleset(8, 16, party)
+	if err := field.GenerateVirusLevel(20, rand.New(rand.NewSource(4))); err != nil {
+		t.Fatalf("party level failed %v", err)
+	}
+
+	greens := 0
+	for y := 0; y < field.GetHeight(); y++ {
+		for x := 0; x < field.GetWidth(); x++ {
+			space, _ := field.GetSpaceAtCoordinate(y, x)
+			if space.Color == Green {
+				greens += 1
+			}
+		}
+	}
+	if greens == 0 {
+		t.Fatal("party level has no green viruses")
+	}
+
+	// green survives the text and binary formats
+	parsed, err := ParsePlayField(FormatPlayField(field))
+	if err != nil || FormatPlayField(parsed) != FormatPlayField(field) {
+		t.Fatalf("green board did not parse back, %v", err)
+	}
+	data, _ := field.MarshalBinary()
+	decoded, _ := NewPlayFieldWithRuleset(8, 16, party)
+	if err = decoded.UnmarshalBinary(data); err != nil || !decoded.Equal(field) {
+		t.Fatalf("green board did not decode back, %v", err)
+	}
+
+	randomizer, _ := NewCapsuleRandomizerWithRuleset(BagRandomizer, 1, party)
+	seen := make(map[CapsuleColors]bool)
+	for i := 0; i < MaxColorCount*MaxColorCount; i++ {
+		seen[randomizer.NextCapsule()] = true
+	}
+	if len(seen) != MaxColorCount*MaxColorCount {
+		t.Fatalf("party bag dealt %v pairs", len(seen))
+	}
+
+	game, err := NewGame(GameConfig{Level: 5, Seed: 3, Ruleset: party})
+	if err != nil {
+		t.Fatalf("party game failed %v", err)
+	}
+	if game.GetField().GetRuleset() != party {
+		t.Fatal("party game board has the wrong ruleset")
+	}
+}
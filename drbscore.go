@@ -0,0 +1,99 @@
+package drbreakboard
+
+type Speed int
+
+const (
+	Low Speed = iota
+	Med
+	Hi
+)
+
+// points awarded for clearing a virus
+// dropVirusIndex is how many viruses were already cleared by the same drop
+type PointTable func(speed Speed, level int, dropVirusIndex int) int
+
+// classic points, 100 for the first virus of a drop at low speed and double
+// for each further virus up to the sixth. med speed is worth twice low and
+// hi speed three times low. level does not change the score
+func ClassicPointTable(speed Speed, level int, dropVirusIndex int) int {
+	if dropVirusIndex > 5 {
+		dropVirusIndex = 5
+	}
+
+	return 100 * (int(speed) + 1) * (1 << dropVirusIndex)
+}
+
+// keeps score for a player
+// viruses are counted per drop, a drop being everything cleared between
+// locking a capsule and the board coming to rest
+type Scorer struct {
+	speed       Speed
+	level       int
+	table       PointTable
+	dropViruses int
+	total       int
+}
+
+// return a scorer using the classic point table
+func NewScorer(speed Speed, level int) *Scorer {
+	return &Scorer{speed: speed, level: level, table: ClassicPointTable}
+}
+
+// replace the point table, nil restores the classic table
+func (scorer *Scorer) SetPointTable(table PointTable) {
+	if table == nil {
+		table = ClassicPointTable
+	}
+	scorer.table = table
+}
+
+func (scorer *Scorer) GetTotal() int {
+	return scorer.total
+}
+
+// get the number of viruses cleared so far in the current drop
+func (scorer *Scorer) GetDropVirusCount() int {
+	return scorer.dropViruses
+}
+
+// score the viruses cleared by one board iteration in the current drop
+// returns the points awarded
+func (scorer *Scorer) ScoreIteration(result IterationResult) int {
+	return scorer.scoreViruses(result.ClearedVirusCount)
+}
+
+// score the viruses cleared by one chain step in the current drop
+// returns the points awarded
+func (scorer *Scorer) ScoreChainStep(step ChainStep) int {
+	return scorer.scoreViruses(step.VirusesCleared)
+}
+
+// score a fully resolved chain as one drop and end the drop
+// returns the points awarded
+func (scorer *Scorer) ScoreChain(report ChainReport) int {
+	points := 0
+	for _, step := range report.Steps {
+		points += scorer.ScoreChainStep(step)
+	}
+	scorer.EndDrop()
+	return points
+}
+
+// end the current drop so the next virus cleared starts a new count
+// returns the number of viruses cleared in the ended drop
+func (scorer *Scorer) EndDrop() int {
+	viruses := scorer.dropViruses
+	scorer.dropViruses = 0
+	return viruses
+}
+
+// award points for viruses cleared in the current drop
+func (scorer *Scorer) scoreViruses(count int) int {
+	points := 0
+	for i := 0; i < count; i++ {
+		points += scorer.table(scorer.speed, scorer.level, scorer.dropViruses)
+		scorer.dropViruses += 1
+	}
+	scorer.total += points
+	return points
+}
@@ -0,0 +1,69 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+func TestClassicPointTable(t *testing.T) {
+	expected := []int{100, 200, 400, 800, 1600, 3200, 3200}
+	for i, points := range expected {
+		if ClassicPointTable(Low, 0, i) != points {
+			t.Fatalf("low virus %v worth %v", i, ClassicPointTable(Low, 0, i))
+		}
+		if ClassicPointTable(Hi, 0, i) != points*3 {
+			t.Fatalf("hi virus %v worth %v", i, ClassicPointTable(Hi, 0, i))
+		}
+	}
+}
+
+func TestScoreChain(t *testing.T) {
+	// two chain clears three blue viruses and then three red viruses
+	field := makeTwoChainField()
+	report, _ := field.ResolveUntilStable()
+	if report.VirusesCleared != 6 {
+		t.Fatalf("chain cleared %v viruses", report.VirusesCleared)
+	}
+
+	scorer := NewScorer(Med, 5)
+	points := scorer.ScoreChain(report)
+	if points != 200+400+800+1600+3200+6400 {
+		t.Fatalf("chain scored %v", points)
+	}
+	if scorer.GetTotal() != points {
+		t.Fatalf("total was %v", scorer.GetTotal())
+	}
+	if scorer.GetDropVirusCount() != 0 {
+		t.Fatal("drop count not reset after chain")
+	}
+
+	// next drop starts counting over
+	field = makeTwoChainField()
+	result := field.EvaluateBoardIterationStreaks()
+	points = scorer.ScoreIteration(result)
+	if points != 200+400+800 {
+		t.Fatalf("first iteration of new drop scored %v", points)
+	}
+	if scorer.EndDrop() != 3 {
+		t.Fatal("drop virus count was wrong")
+	}
+}
+
+func TestScorerPointTable(t *testing.T) {
+	scorer := NewScorer(Low, 10)
+	scorer.SetPointTable(func(speed Speed, level int, dropVirusIndex int) int {
+		return level
+	})
+
+	field := makeTwoChainField()
+	report, _ := field.ResolveUntilStable()
+	if scorer.ScoreChain(report) != 60 {
+		t.Fatal("house point table not used")
+	}
+
+	scorer.SetPointTable(nil)
+	field = makeTwoChainField()
+	report, _ = field.ResolveUntilStable()
+	if scorer.ScoreChain(report) != 6300 {
+		t.Fatal("classic point table not restored")
+	}
+}
@@ -0,0 +1,122 @@
+package drbreakboard
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// write the board as text, one row per line with each space as its
+// three letter code followed by a space and a blank line at the end.
+// this is the format DrawBoard prints and ParsePlayField reads
+func FormatPlayField(field *PlayField) string {
+	var sb strings.Builder
+
+	for y := 0; y < field.GetHeight(); y++ {
+		for x := 0; x < field.GetWidth(); x++ {
+			space, _ := field.GetSpaceAtCoordinate(y, x)
+			sb.WriteString(generateRawSpaceString(space))
+			sb.WriteString(" ")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// read a board written by FormatPlayField
+// spaces are separated by whitespace and blank lines are ignored.
+// errors if rows differ in width, a code is unknown or the linkages
+// do not make a legal board
+func ParsePlayField(text string) (*PlayField, error) {
+	rows := make([][]Space, 0)
+
+	for lineNumber, line := range strings.Split(text, "\n") {
+		codes := strings.Fields(line)
+		if len(codes) == 0 {
+			continue
+		}
+
+		if len(rows) > 0 && len(codes) != len(rows[0]) {
+			return nil, fmt.Errorf("line %v has %v spaces, expected %v", lineNumber+1, len(codes), len(rows[0]))
+		}
+
+		row := make([]Space, len(codes))
+		for x, code := range codes {
+			space, err := parseRawSpaceString(code)
+			if err != nil {
+				return nil, fmt.Errorf("line %v space %v: %w", lineNumber+1, x+1, err)
+			}
+			row[x] = space
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, errors.New("no board rows found")
+	}
+
+	field := NewPlayField(len(rows[0]), len(rows))
+	field.spaces = rows
+	field.syncBitboards()
+
+	if err := violationsError(field.Validate()); err != nil {
+		return nil, err
+	}
+
+	return field, nil
+}
+
+// read a three letter space code written by generateRawSpaceString
+func parseRawSpaceString(code string) (Space, error) {
+	if len(code) != 3 {
+		return Space{}, fmt.Errorf("space code %q is not three letters", code)
+	}
+
+	space := Space{}
+
+	switch code[0] {
+	case 'X':
+		space.Content = Empty
+	case 'V':
+		space.Content = Virus
+	case 'P':
+		space.Content = Pill
+	default:
+		return Space{}, fmt.Errorf("unknown content in space code %q", code)
+	}
+
+	switch code[1] {
+	case 'X':
+		space.Color = Uncolored
+	case 'R':
+		space.Color = Red
+	case 'B':
+		space.Color = Blue
+	case 'Y':
+		space.Color = Yellow
+	case 'G':
+		space.Color = Green
+	default:
+		return Space{}, fmt.Errorf("unknown color in space code %q", code)
+	}
+
+	switch code[2] {
+	case 'X':
+		space.Linkage = Unlinked
+	case 'U':
+		space.Linkage = Up
+	case 'D':
+		space.Linkage = Down
+	case 'L':
+		space.Linkage = Left
+	case 'R':
+		space.Linkage = Right
+	default:
+		return Space{}, fmt.Errorf("unknown linkage in space code %q", code)
+	}
+
+	return space, nil
+}
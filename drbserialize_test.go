@@ -0,0 +1,79 @@
+package drbreakboard
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatAndParsePlayField(t *testing.T) {
+	field := makeTwoChainField()
+	text := FormatPlayField(field)
+
+	parsed, err := ParsePlayField(text)
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+
+	if parsed.GetWidth() != field.GetWidth() || parsed.GetHeight() != field.GetHeight() {
+		t.Fatalf("parsed board is %vx%v", parsed.GetWidth(), parsed.GetHeight())
+	}
+
+	if FormatPlayField(parsed) != text {
+		t.Fatal("parsed board formats differently")
+	}
+
+	// parsed board iterates like the original
+	report, _ := parsed.ResolveUntilStable()
+	if report.ChainDepth != 2 {
+		t.Fatalf("parsed board chain depth %v", report.ChainDepth)
+	}
+}
+
+func TestParsePlayFieldFixture(t *testing.T) {
+	fixture := `
+XXX XXX XXX XXX
+XXX PRD XXX XXX
+XXX PBU PYR PBL
+VRX VBX VYX XXX
+`
+	field, err := ParsePlayField(fixture)
+	if err != nil {
+		t.Fatalf("parse failed %v", err)
+	}
+
+	if field.GetWidth() != 4 || field.GetHeight() != 4 {
+		t.Fatalf("fixture parsed as %vx%v", field.GetWidth(), field.GetHeight())
+	}
+
+	space, _ := field.GetSpaceAtCoordinate(2, 2)
+	if space != (Space{Pill, Right, Yellow}) {
+		t.Fatalf("space 2,2 parsed as %v", space)
+	}
+
+	if field.GetVirusCount() != 3 {
+		t.Fatalf("fixture has %v viruses", field.GetVirusCount())
+	}
+}
+
+func TestParsePlayFieldErrors(t *testing.T) {
+	bad := map[string]string{
+		"empty":           "\n\n",
+		"ragged rows":     "XXX XXX\nXXX\n",
+		"unknown code":    "XXX QQQ\n",
+		"short code":      "XX XXX\n",
+		"dangling link":   "PRR XXX\n",
+		"out of bounds":   "XXX PRR\n",
+		"mismatched link": "PRR PBR\n",
+		"linked virus":    "VRR PBL\n",
+	}
+
+	for name, text := range bad {
+		_, err := ParsePlayField(text)
+		if err == nil {
+			t.Fatalf("%v parsed without error", name)
+		}
+		if strings.TrimSpace(err.Error()) == "" {
+			t.Fatalf("%v error had no message", name)
+		}
+	}
+}
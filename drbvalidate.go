@@ -0,0 +1,128 @@
+package drbreakboard
+
+import (
+	"fmt"
+)
+
+type ViolationKind int
+
+const (
+	// linked space with no pill to link to
+	DanglingLinkage ViolationKind = iota
+	// linked space pointing off the board
+	OutOfBoundsLinkage
+	// linked pill whose partner is linked somewhere else
+	MismatchedLinkage
+	// virus with a linkage
+	LinkedVirus
+	// empty space with a color
+	ColoredEmpty
+	// pill with no color
+	UncoloredPill
+	// virus with no color
+	UncoloredVirus
+)
+
+// a broken board rule at a space
+type Violation struct {
+	Kind       ViolationKind
+	Coordinate Coordinate
+}
+
+func (kind ViolationKind) String() string {
+	switch kind {
+	case DanglingLinkage:
+		return "dangling linkage"
+	case OutOfBoundsLinkage:
+		return "linkage out of bounds"
+	case MismatchedLinkage:
+		return "mismatched linkage"
+	case LinkedVirus:
+		return "linked virus"
+	case ColoredEmpty:
+		return "colored empty space"
+	case UncoloredPill:
+		return "uncolored pill"
+	case UncoloredVirus:
+		return "uncolored virus"
+	}
+
+	return "unknown violation"
+}
+
+func (violation Violation) String() string {
+	return fmt.Sprintf("%v at %v,%v", violation.Kind, violation.Coordinate.y, violation.Coordinate.x)
+}
+
+// check the board is legal and return every violation found,
+// nil if the board is legal
+func (field *PlayField) Validate() []Violation {
+	var violations []Violation
+
+	report := func(kind ViolationKind, y int, x int) {
+		violations = append(violations, Violation{kind, Coordinate{y, x}})
+	}
+
+	for y, row := range field.spaces {
+		for x, space := range row {
+			switch space.Content {
+			case Empty:
+				if space.Color != Uncolored {
+					report(ColoredEmpty, y, x)
+				}
+				if space.Linkage != Unlinked {
+					report(DanglingLinkage, y, x)
+				}
+				continue
+			case Virus:
+				if space.Color == Uncolored {
+					report(UncoloredVirus, y, x)
+				}
+				if space.Linkage != Unlinked {
+					report(LinkedVirus, y, x)
+				}
+				continue
+			case Pill:
+				if space.Color == Uncolored {
+					report(UncoloredPill, y, x)
+				}
+			}
+
+			if space.Linkage == Unlinked {
+				continue
+			}
+
+			// linked pill, check the partner links back
+			linkedY, linkedX, _ := GetLinkedCoordinate(y, x, space.Linkage)
+			linked, err := field.GetSpaceAtCoordinate(linkedY, linkedX)
+			switch {
+			case err != nil:
+				report(OutOfBoundsLinkage, y, x)
+			case linked.Content != Pill:
+				report(DanglingLinkage, y, x)
+			case linked.Linkage != getOpposingLinkage(space.Linkage):
+				report(MismatchedLinkage, y, x)
+			}
+		}
+	}
+
+	return violations
+}
+
+// turn violations into an error naming the first, nil if there are none
+func violationsError(violations []Violation) error {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("board has %v violations, first is %v", len(violations), violations[0])
+}
+
+// validate the board in builds with the drbdebug tag
+func (field *PlayField) debugValidate() error {
+	if !debugValidation {
+		return nil
+	}
+
+	return violationsError(field.Validate())
+}
@@ -0,0 +1,58 @@
+package drbreakboard
+
+import (
+	"testing"
+)
+
+func TestValidateLegalBoard(t *testing.T) {
+	field := makeTwoChainField()
+	if violations := field.Validate(); violations != nil {
+		t.Fatalf("legal board had violations %v", violations)
+	}
+
+	field.ResolveUntilStable()
+	if violations := field.Validate(); violations != nil {
+		t.Fatalf("resolved board had violations %v", violations)
+	}
+}
+
+func TestValidateViolations(t *testing.T) {
+	field := NewPlayField(4, 3)
+
+	// set spaces directly, the put functions refuse to make these
+	field.spaces[0][0] = Space{Pill, Right, Red}    // partner is empty
+	field.spaces[0][3] = Space{Pill, Right, Blue}   // partner off the board
+	field.spaces[1][0] = Space{Pill, Right, Red}    // partner links elsewhere
+	field.spaces[1][1] = Space{Pill, Right, Yellow} // linked back correctly
+	field.spaces[1][2] = Space{Pill, Left, Yellow}
+	field.spaces[2][0] = Space{Virus, Up, Red}        // linked virus
+	field.spaces[0][1] = Space{Empty, Unlinked, Blue} // colored empty
+	field.spaces[2][2] = Space{Pill, Unlinked, Uncolored}
+	field.spaces[2][3] = Space{Virus, Unlinked, Uncolored}
+
+	expected := map[Coordinate]ViolationKind{
+		{0, 0}: DanglingLinkage,
+		{0, 3}: OutOfBoundsLinkage,
+		{1, 0}: MismatchedLinkage,
+		{2, 0}: LinkedVirus,
+		{0, 1}: ColoredEmpty,
+		{2, 2}: UncoloredPill,
+		{2, 3}: UncoloredVirus,
+	}
+
+	violations := field.Validate()
+	if len(violations) != len(expected) {
+		t.Fatalf("expected %v violations, got %v", len(expected), violations)
+	}
+
+	for _, violation := range violations {
+		kind, ok := expected[violation.Coordinate]
+		if !ok || kind != violation.Kind {
+			t.Fatalf("unexpected violation %v", violation)
+		}
+	}
+
+	if violationsError(violations) == nil || violationsError(nil) != nil {
+		t.Fatal("violations error did not match violations")
+	}
+}